@@ -39,7 +39,7 @@ comments, and history to minimal metadata.`,
 				return err
 			}
 			if len(compacted) == 0 {
-				fmt.Println("No done/cancelled issues to compact")
+				fmt.Println("No issues in terminal states to compact")
 				return nil
 			}
 			fmt.Printf("Compacted %d issues\n", len(compacted))
@@ -63,7 +63,7 @@ comments, and history to minimal metadata.`,
 }
 
 func init() {
-	compactCmd.Flags().BoolVar(&compactAllDone, "all-done", false, "Compact all done/cancelled issues")
+	compactCmd.Flags().BoolVar(&compactAllDone, "all-done", false, "Compact all issues in terminal states")
 	compactCmd.Flags().BoolVar(&compactRewrite, "rewrite", false, "Rewrite all issues to current on-disk format")
 	rootCmd.AddCommand(compactCmd)
 }
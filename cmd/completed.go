@@ -1,9 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bufio"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
@@ -31,76 +34,83 @@ var completedCmd = &cobra.Command{
 			return err
 		}
 
-		entries, err := t.LoadLog()
-		if err != nil {
-			return err
-		}
-
+		var since time.Time
 		if completedSince != "" {
-			since, err := parseTimeFlag(completedSince)
-			if err != nil {
+			if since, err = parseTimeFlag(completedSince); err != nil {
 				return err
 			}
-			var filtered []tracker.LogEntry
-			for _, e := range entries {
-				if !e.Closed.Before(since) {
-					filtered = append(filtered, e)
-				}
+		}
+		keep := func(e tracker.LogEntry) bool {
+			if !since.IsZero() && e.Closed.Before(since) {
+				return false
+			}
+			if completedLabel != "" && !slices.Contains(e.Labels, completedLabel) {
+				return false
 			}
-			entries = filtered
+			return completedType == "" || e.Type == completedType
 		}
-		if completedLabel != "" {
-			var filtered []tracker.LogEntry
-			for _, e := range entries {
-				for _, l := range e.Labels {
-					if l == completedLabel {
-						filtered = append(filtered, e)
-						break
+
+		// entries streams the matching log entries. With --last only the
+		// tail is kept, which means reading the whole log first.
+		entries := func(yield func(tracker.LogEntry, error) bool) {
+			var tail []tracker.LogEntry
+			for e, err := range t.LogEntries() {
+				if err != nil {
+					yield(e, err)
+					return
+				}
+				if !keep(e) {
+					continue
+				}
+				if completedLast <= 0 {
+					if !yield(e, nil) {
+						return
 					}
+					continue
+				}
+				if len(tail) == completedLast {
+					tail = tail[1:]
 				}
+				tail = append(tail, e)
 			}
-			entries = filtered
-		}
-		if completedType != "" {
-			var filtered []tracker.LogEntry
-			for _, e := range entries {
-				if e.Type == completedType {
-					filtered = append(filtered, e)
+			for _, e := range tail {
+				if !yield(e, nil) {
+					return
 				}
 			}
-			entries = filtered
 		}
 
-		if completedLast > 0 && len(entries) > completedLast {
-			entries = entries[len(entries)-completedLast:]
-		}
+		w := bufio.NewWriter(os.Stdout)
+		defer func() { _ = w.Flush() }()
 
 		if completedFormat == "json" {
-			data, err := json.MarshalIndent(entries, "", "  ")
+			return writeJSONArray(w, entries)
+		}
+
+		// Short IDs are unique among the listed entries, so collect
+		// just the IDs in a first pass and stream the lines in a second.
+		var ids []string
+		for e, err := range entries {
 			if err != nil {
 				return err
 			}
-			fmt.Println(string(data))
-			return nil
+			ids = append(ids, e.ID)
 		}
-
-		if len(entries) == 0 {
+		if len(ids) == 0 {
 			fmt.Println("No completions")
 			return nil
 		}
-
-		ids := make([]string, len(entries))
-		for i, e := range entries {
-			ids[i] = e.ID
-		}
 		short := tracker.MinPrefixes(ids)
 
-		for _, e := range entries {
+		for e, err := range entries {
+			if err != nil {
+				return err
+			}
 			labels := ""
 			if len(e.Labels) > 0 {
 				labels = " [" + strings.Join(e.Labels, ",") + "]"
 			}
-			fmt.Printf("%s  %s  %s  %s%s\n", e.Closed.Format("2006-01-02"), short[e.ID], e.Status, e.Title, labels)
+			_, _ = fmt.Fprintf(w, "%s  %s  %s  %s%s\n", e.Closed.Format("2006-01-02"), short[e.ID], e.Status, e.Title, labels)
 		}
 		return nil
 	},
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorFix    bool
+	doctorFormat string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the tracker for integrity problems",
+	Long: `Check .work/ for problems such as unparseable files, children
+whose parent no longer exists, statuses or types missing from the
+config, and duplicate log entries. Each problem is reported with a
+machine-readable code. With --fix, problems that can be repaired
+automatically are fixed. Exits non-zero if any problem remains.`,
+	Example: `  work doctor
+  work doctor --fix
+  work doctor --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		problems, err := t.Doctor(doctorFix, cfg.User)
+		if err != nil {
+			return err
+		}
+
+		remaining := 0
+		for _, p := range problems {
+			if !p.Fixed {
+				remaining++
+			}
+		}
+
+		if doctorFormat == "json" {
+			data, err := json.MarshalIndent(problems, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		} else {
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+			}
+			for _, p := range problems {
+				where := p.IssueID
+				if p.Line > 0 {
+					where = fmt.Sprintf("%s:%d", where, p.Line)
+				}
+				status := ""
+				switch {
+				case p.Fixed:
+					status = " (fixed)"
+				case p.Fixable:
+					status = " (fixable with --fix)"
+				}
+				fmt.Printf("%-20s %-12s %s%s\n", p.Code, where, p.Message, status)
+			}
+		}
+
+		if remaining > 0 {
+			return fmt.Errorf("%d problem(s) found", remaining)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair problems that can be fixed automatically")
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", "", "Output format (json)")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/jfmyers9/work/internal/editor"
 	"github.com/jfmyers9/work/internal/model"
@@ -19,15 +18,20 @@ var (
 	editLabels      string
 	editAssignee    string
 	editType        string
+	editState       string
 )
 
 var editCmd = &cobra.Command{
 	Use:   "edit <id>",
 	Short: "Edit an issue",
-	Long:  `Update fields on an existing issue. If no flags are given, opens the issue in $EDITOR.`,
+	Long: `Update fields on an existing issue. If no flags are given, opens the issue in $EDITOR.
+
+Changing the type to one whose workflow lacks the issue's current
+state needs --state to say which state it moves to.`,
 	Example: `  work edit abc123
   work edit abc123 --title "Updated title"
-  work edit abc --priority 2 --labels urgent,backend`,
+  work edit abc --priority 2 --labels urgent,backend
+  work edit abc --type chore --state open`,
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: completeIssueIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -44,54 +48,42 @@ var editCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if !editFlagsChanged(cmd) {
+			return editInEditor(t, issue)
+		}
 
-		var edited []string
+		edited := issue
 		if cmd.Flags().Changed("title") {
-			issue.Title = editTitle
-			edited = append(edited, "title")
+			edited.Title = editTitle
 		}
 		if cmd.Flags().Changed("description") {
-			issue.Description = editDescription
-			edited = append(edited, "description")
+			edited.Description = editDescription
 		}
 		if cmd.Flags().Changed("assignee") {
-			issue.Assignee = editAssignee
-			edited = append(edited, "assignee")
+			edited.Assignee = editAssignee
 		}
 		if cmd.Flags().Changed("priority") {
-			issue.Priority = editPriority
-			edited = append(edited, "priority")
+			edited.Priority = editPriority
 		}
 		if cmd.Flags().Changed("labels") {
-			issue.Labels = strings.Split(editLabels, ",")
-			edited = append(edited, "labels")
+			edited.Labels = strings.Split(editLabels, ",")
 		}
 		if cmd.Flags().Changed("type") {
-			if err := tracker.ValidateType(t.Config, editType); err != nil {
-				return err
-			}
-			issue.Type = editType
-			edited = append(edited, "type")
+			edited.Type = editType
 		}
-
-		if len(edited) == 0 {
-			return editInEditor(t, issue)
+		if cmd.Flags().Changed("state") {
+			if !cmd.Flags().Changed("type") {
+				return fmt.Errorf("--state maps the status for a --type change; use 'work status' otherwise")
+			}
+			edited.Status = editState
 		}
 
-		now := time.Now().UTC()
-		issue.Updated = now
-		if err := t.SaveIssue(issue); err != nil {
+		_, conflicts, err := t.SaveEdit(issue, edited, cfg.User)
+		if err != nil {
 			return err
 		}
-
-		event := model.Event{
-			Timestamp: now,
-			Op:        "edit",
-			Fields:    edited,
-			By:        cfg.User,
-		}
-		if err := t.AppendEvent(id, event); err != nil {
-			return err
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%s was changed by someone else while editing; re-run the edit", shortID(t, id))
 		}
 		fmt.Printf("Updated %s\n", shortID(t, id))
 		return nil
@@ -99,87 +91,60 @@ var editCmd = &cobra.Command{
 }
 
 func editInEditor(t *tracker.Tracker, issue model.Issue) error {
+	base := issue
 	content := editor.MarshalIssue(issue)
-	result, err := editor.OpenEditor(content, "work-edit", cfg.Editor)
-	if err != nil {
-		if errors.Is(err, editor.ErrAborted) {
-			fmt.Println("edit cancelled")
-			return nil
+	for {
+		result, err := editor.OpenEditor(content, "work-edit", cfg.Editor)
+		if err != nil {
+			if errors.Is(err, editor.ErrAborted) {
+				fmt.Println("edit cancelled")
+				return nil
+			}
+			return err
 		}
-		return err
-	}
 
-	title, description, issueType, assignee, priority, labels, err := editor.UnmarshalIssue(result)
-	if err != nil {
-		return err
-	}
-
-	var edited []string
-	if title != issue.Title {
-		issue.Title = title
-		edited = append(edited, "title")
-	}
-	if description != issue.Description {
-		issue.Description = description
-		edited = append(edited, "description")
-	}
-	if issueType != issue.Type {
-		if err := tracker.ValidateType(t.Config, issueType); err != nil {
+		title, description, issueType, assignee, priority, labels, err := editor.UnmarshalIssue(result)
+		if err != nil {
 			return err
 		}
-		issue.Type = issueType
-		edited = append(edited, "type")
-	}
-	if assignee != issue.Assignee {
-		issue.Assignee = assignee
-		edited = append(edited, "assignee")
-	}
-	if priority != issue.Priority {
-		issue.Priority = priority
-		edited = append(edited, "priority")
-	}
-	if !labelsEqual(issue.Labels, labels) {
-		issue.Labels = labels
-		edited = append(edited, "labels")
-	}
+		mine := base
+		mine.Title = title
+		mine.Description = description
+		mine.Type = issueType
+		mine.Assignee = assignee
+		mine.Priority = priority
+		mine.Labels = labels
 
-	if len(edited) == 0 {
-		fmt.Println("edit cancelled")
-		return nil
-	}
+		if len(tracker.ChangedFields(base, mine)) == 0 {
+			fmt.Println("edit cancelled")
+			return nil
+		}
 
-	now := time.Now().UTC()
-	issue.Updated = now
-	if err := t.SaveIssue(issue); err != nil {
-		return err
-	}
+		current, conflicts, err := t.SaveEdit(base, mine, cfg.User)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) == 0 {
+			fmt.Printf("Updated %s\n", shortID(t, issue.ID))
+			return nil
+		}
 
-	event := model.Event{
-		Timestamp: now,
-		Op:        "edit",
-		Fields:    edited,
-		By:        cfg.User,
-	}
-	if err := t.AppendEvent(issue.ID, event); err != nil {
-		return err
+		// Someone else changed the same fields while the editor was open.
+		// Re-open on top of their version, keeping ours for the clashing
+		// fields, and let the user reconcile.
+		reopen, _ := tracker.MergeEdits(base, current, mine)
+		base = current
+		content = editor.ConflictNotice(tracker.SavedValues(conflicts)) + editor.MarshalIssue(reopen)
 	}
-	fmt.Printf("Updated %s\n", shortID(t, issue.ID))
-	return nil
 }
 
-func labelsEqual(a, b []string) bool {
-	if len(a) == 0 && len(b) == 0 {
-		return true
-	}
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
+func editFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range []string{"title", "description", "assignee", "priority", "labels", "type", "state"} {
+		if cmd.Flags().Changed(name) {
+			return true
 		}
 	}
-	return true
+	return false
 }
 
 func init() {
@@ -189,5 +154,6 @@ func init() {
 	editCmd.Flags().StringVar(&editLabels, "labels", "", "Replace labels (comma-separated)")
 	editCmd.Flags().StringVar(&editAssignee, "assignee", "", "New assignee")
 	editCmd.Flags().StringVar(&editType, "type", "", "New type (feature|bug|chore)")
+	editCmd.Flags().StringVar(&editState, "state", "", "State to map the issue to when --type changes its workflow")
 	rootCmd.AddCommand(editCmd)
 }
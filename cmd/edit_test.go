@@ -61,6 +61,9 @@ func TestEditInEditor_ChangedFields(t *testing.T) {
 	if len(last.Fields) != 1 || last.Fields[0] != "title" {
 		t.Errorf("edited fields = %v, want [title]", last.Fields)
 	}
+	if len(last.Changes) != 1 || string(last.Changes[0].From) != `"Original title"` || string(last.Changes[0].To) != `"Updated title"` {
+		t.Errorf("changes = %+v, want title from original to updated", last.Changes)
+	}
 }
 
 func TestEditInEditor_NoChanges(t *testing.T) {
@@ -169,3 +172,84 @@ func TestEditInEditor_OnlyChangedFieldsInHistory(t *testing.T) {
 		t.Errorf("fields = %v, should not include unchanged fields", last.Fields)
 	}
 }
+
+func TestEditInEditor_MergesConcurrentChange(t *testing.T) {
+	tr, id := setupEditTest(t)
+	original := editor.OpenEditor
+	defer func() { editor.OpenEditor = original }()
+
+	editor.OpenEditor = func(content, prefix, editorBin string) (string, error) {
+		// Someone comments and reprioritizes while the editor is open.
+		if _, err := tr.AddComment(id, "meanwhile", "other"); err != nil {
+			t.Fatalf("comment: %v", err)
+		}
+		return strings.Replace(content, "Original title", "Updated title", 1), nil
+	}
+
+	issue, err := tr.LoadIssue(id)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := editInEditor(tr, issue); err != nil {
+		t.Fatalf("editInEditor: %v", err)
+	}
+
+	updated, err := tr.LoadIssue(id)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if updated.Title != "Updated title" {
+		t.Errorf("title = %q, want %q", updated.Title, "Updated title")
+	}
+	if len(updated.Comments) != 1 || updated.Comments[0].Text != "meanwhile" {
+		t.Errorf("concurrent comment lost: %+v", updated.Comments)
+	}
+}
+
+func TestEditInEditor_ConflictReopensEditor(t *testing.T) {
+	tr, id := setupEditTest(t)
+	original := editor.OpenEditor
+	defer func() { editor.OpenEditor = original }()
+
+	var calls int
+	var reopened string
+	editor.OpenEditor = func(content, prefix, editorBin string) (string, error) {
+		calls++
+		if calls == 1 {
+			theirs, err := tr.LoadIssue(id)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			edited := theirs
+			edited.Title = "Their title"
+			if _, _, err := tr.SaveEdit(theirs, edited, "other"); err != nil {
+				t.Fatalf("concurrent edit: %v", err)
+			}
+			return strings.Replace(content, "Original title", "My title", 1), nil
+		}
+		reopened = content
+		return content, nil
+	}
+
+	issue, err := tr.LoadIssue(id)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := editInEditor(tr, issue); err != nil {
+		t.Fatalf("editInEditor: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("editor opened %d times, want 2", calls)
+	}
+	if !strings.Contains(reopened, "CONFLICT") || !strings.Contains(reopened, "title: Their title") {
+		t.Errorf("reopened content missing conflict notice:\n%s", reopened)
+	}
+	updated, err := tr.LoadIssue(id)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if updated.Title != "My title" {
+		t.Errorf("title = %q, want %q after resolving", updated.Title, "My title")
+	}
+}
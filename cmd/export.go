@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
+	"bufio"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -18,16 +18,9 @@ var exportCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		issues, err := t.ListIssues()
-		if err != nil {
-			return err
-		}
-		data, err := json.MarshalIndent(issues, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(data))
-		return nil
+		w := bufio.NewWriter(os.Stdout)
+		defer func() { _ = w.Flush() }()
+		return writeJSONArray(w, t.Issues())
 	},
 }
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/jfmyers9/work/internal/model"
@@ -11,8 +10,9 @@ import (
 )
 
 var (
-	gcDays int
-	gcKeep int
+	gcDays    int
+	gcKeep    int
+	gcArchive bool
 )
 
 var gcCmd = &cobra.Command{
@@ -22,92 +22,69 @@ var gcCmd = &cobra.Command{
 (age threshold, default 30) and/or --keep N (keep only the
 last N completed issues). When both are given, an issue is
 only purged if it exceeds BOTH thresholds. Metadata is
-preserved in .work/log.jsonl.`,
+preserved in .work/log.jsonl.
+
+With --archive, the full issue directories, history included, are
+moved into .work/archive/<yyyy-mm>.tar.gz (by month closed) instead
+of being deleted. Use 'work restore <id>' to bring one back.`,
 	Example: `  work gc
   work gc --days 7
-  work gc --keep 10`,
+  work gc --keep 10
+  work gc --archive`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		t, err := loadTracker()
 		if err != nil {
 			return err
 		}
-
-		allIssues, err := t.ListIssues()
-		if err != nil {
-			return err
-		}
-
-		// Collect done/cancelled issues sorted by updated desc (newest first)
-		var completed []model.Issue
-		for _, issue := range allIssues {
-			if issue.Status == "done" || issue.Status == "cancelled" {
-				completed = append(completed, issue)
-			}
-		}
-		sort.Slice(completed, func(i, j int) bool {
-			return completed[i].Updated.After(completed[j].Updated)
-		})
-
-		// Determine which issues to purge
-		cutoff := time.Now().UTC().AddDate(0, 0, -gcDays)
-		keepByCount := make(map[string]bool)
-		if gcKeep > 0 {
-			for i, issue := range completed {
-				if i < gcKeep {
-					keepByCount[issue.ID] = true
-				}
-			}
-		}
-
 		daysSet := cmd.Flags().Changed("days")
 		keepSet := gcKeep > 0
 
-		var toPurge []model.Issue
-		for _, issue := range completed {
-			oldEnough := issue.Updated.Before(cutoff)
-			beyondKeep := keepSet && !keepByCount[issue.ID]
-
-			switch {
-			case keepSet && daysSet:
-				// Both flags: purge only if exceeds both thresholds
-				if beyondKeep && oldEnough {
-					toPurge = append(toPurge, issue)
-				}
-			case keepSet:
-				// --keep only: purge everything beyond the limit
-				if beyondKeep {
-					toPurge = append(toPurge, issue)
-				}
-			default:
-				// --days only (or neither, using default 30)
-				if oldEnough {
-					toPurge = append(toPurge, issue)
+		// completed is newest first.
+		pick := func(completed []model.Issue) []model.Issue {
+			cutoff := time.Now().UTC().AddDate(0, 0, -gcDays)
+			var toPurge []model.Issue
+			for i, issue := range completed {
+				oldEnough := issue.Updated.Before(cutoff)
+				beyondKeep := keepSet && i >= gcKeep
+
+				switch {
+				case keepSet && daysSet:
+					// Both flags: purge only if exceeds both thresholds
+					if beyondKeep && oldEnough {
+						toPurge = append(toPurge, issue)
+					}
+				case keepSet:
+					// --keep only: purge everything beyond the limit
+					if beyondKeep {
+						toPurge = append(toPurge, issue)
+					}
+				default:
+					// --days only (or neither, using default 30)
+					if oldEnough {
+						toPurge = append(toPurge, issue)
+					}
 				}
 			}
+			return toPurge
 		}
 
-		if len(toPurge) == 0 {
+		purged, deduped, err := t.CollectIssues(pick, gcArchive)
+		if err != nil {
+			return err
+		}
+		if len(purged) == 0 {
 			fmt.Println("No issues to purge")
 			return nil
 		}
 
-		var purged []string
-		for _, issue := range toPurge {
-			if err := t.PurgeIssue(issue); err != nil {
-				return err
-			}
-			purged = append(purged, issue.ID)
-		}
-
-		deduped, err := t.DeduplicateLog()
-		if err != nil {
-			return fmt.Errorf("deduplicating log: %w", err)
-		}
-
 		short := tracker.MinPrefixes(purged)
 
-		fmt.Printf("Purged %d issues\n", len(purged))
+		verb := "Purged"
+		if gcArchive {
+			verb = "Archived"
+		}
+		fmt.Printf("%s %d issues\n", verb, len(purged))
 		for _, id := range purged {
 			fmt.Printf("  %s\n", short[id])
 		}
@@ -122,5 +99,6 @@ preserved in .work/log.jsonl.`,
 func init() {
 	gcCmd.Flags().IntVar(&gcDays, "days", 30, "Age threshold in days")
 	gcCmd.Flags().IntVar(&gcKeep, "keep", 0, "Keep only the last N completed issues")
+	gcCmd.Flags().BoolVar(&gcArchive, "archive", false, "Move issues into .work/archive/ instead of deleting them")
 	rootCmd.AddCommand(gcCmd)
 }
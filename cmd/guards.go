@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+	"github.com/jfmyers9/work/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// addTransitionFlags registers the flags of commands that change status:
+// --force, and those supplying what transition guards ask for.
+func addTransitionFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("force", false, "Ignore WIP limits")
+	cmd.Flags().String("reason", "", "Reason comment, if the new state requires one")
+	cmd.Flags().String("assignee", "", "Assignee to set, if the new state requires one")
+	cmd.Flags().Int("priority", 0, "Priority to set, if the new state requires one in range")
+}
+
+// meetGuards supplies what the guards on entering state to find missing
+// from issue, taking each value from its flag or, on a terminal, asking
+// for it. Nothing is changed unless every unmet guard can be met.
+func meetGuards(cmd *cobra.Command, t *tracker.Tracker, issue model.Issue, to string) error {
+	err := t.CheckGuards(issue, to)
+	var guardErr *tracker.GuardError
+	if !errors.As(err, &guardErr) {
+		return err
+	}
+
+	edited := issue
+	var reason string
+	for _, u := range guardErr.Unmet {
+		switch u.Require {
+		case model.RequireAssignee:
+			v, ok := guardInput(cmd, "assignee", "Assignee")
+			if !ok {
+				return guardErr
+			}
+			edited.Assignee = v
+		case model.RequirePriority:
+			v, ok := guardInput(cmd, "priority", "Priority")
+			if !ok {
+				return guardErr
+			}
+			p, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid priority %q", v)
+			}
+			edited.Priority = p
+		case model.RequireComment:
+			v, ok := guardInput(cmd, "reason", "Reason")
+			if !ok {
+				return guardErr
+			}
+			reason = v
+		default:
+			return guardErr
+		}
+	}
+
+	// Check the supplied values before writing any of them.
+	check := edited
+	if reason != "" {
+		check.Comments = append(slices.Clone(issue.Comments), model.Comment{Text: reason, Created: time.Now().UTC()})
+	}
+	if err := t.CheckGuards(check, to); err != nil {
+		return err
+	}
+	if len(tracker.ChangedFields(issue, edited)) > 0 {
+		_, conflicts, err := t.SaveEdit(issue, edited, cfg.User)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%s was changed by someone else; re-run the command", shortID(t, issue.ID))
+		}
+	}
+	if reason != "" {
+		if _, err := t.AddComment(issue.ID, reason, cfg.User); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardInput returns the value of the named flag if given, else asks for
+// it when stdin is a terminal. It reports false when there is no value.
+func guardInput(cmd *cobra.Command, flag, label string) (string, bool) {
+	if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+		return f.Value.String(), true
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", false
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", false
+	}
+	line = strings.TrimSpace(line)
+	return line, line != ""
+}
@@ -1,29 +1,132 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/jfmyers9/work/internal/config"
 	"github.com/jfmyers9/work/internal/model"
 	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
+// startDir returns the directory the command runs from: the -C path if
+// given, otherwise the working directory.
+func startDir() (string, error) {
+	if chdir != "" {
+		dir, err := filepath.Abs(chdir)
+		if err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(dir); err != nil {
+			return "", err
+		} else if !info.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", chdir)
+		}
+		return dir, nil
+	}
+	return os.Getwd()
+}
+
+// workDirOverride returns the tracker root named by WORK_DIR, resolved
+// against start, or "" if it is unset.
+func workDirOverride(start string) string {
+	if cfg.Dir == "" || filepath.IsAbs(cfg.Dir) {
+		return cfg.Dir
+	}
+	return filepath.Join(start, cfg.Dir)
+}
+
+// trackerRoot returns the directory holding .work/: the WORK_DIR
+// override if set, otherwise the nearest one found by walking up from
+// the start directory.
+func trackerRoot() (string, error) {
+	dir, err := startDir()
+	if err != nil {
+		return "", err
+	}
+	if root := workDirOverride(dir); root != "" {
+		return root, nil
+	}
+	return tracker.FindRoot(dir)
+}
+
 func loadTracker() (*tracker.Tracker, error) {
-	wd, err := os.Getwd()
+	root, err := trackerRoot()
 	if err != nil {
 		return nil, err
 	}
-	t, err := tracker.Load(wd)
+	t, err := tracker.Load(root)
 	if err != nil {
 		return nil, err
 	}
+	t.LockTimeout = cfg.LockTimeout
+	t.HookOutput = os.Stderr
+	t.NoAutomation = noAutomation
+	for _, msg := range t.Recovered {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+	}
+	if t.NeedsMigration() && !migrating {
+		fmt.Fprintf(os.Stderr, "warning: .work/ is at schema version %d (current is %d); run 'work migrate' to upgrade\n",
+			t.Config.SchemaVersion, tracker.SchemaVersion)
+	}
+	loaded = append(loaded, t)
 	return t, nil
 }
 
+// loaded holds the trackers opened by the running command, so that lines
+// skipped by lenient reads can be reported once it finishes.
+var loaded []*tracker.Tracker
+
+func reportWarnings() {
+	n := 0
+	for _, t := range loaded {
+		for _, w := range t.Warnings() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			n++
+		}
+	}
+	if n > 0 {
+		fmt.Fprintln(os.Stderr, "Run 'work doctor' to inspect and repair the tracker.")
+	}
+}
+
+// writeJSONArray writes the items of seq as an indented JSON array, one
+// item at a time, so output starts before the sequence is exhausted.
+// The result matches json.MarshalIndent(items, "", "  ") except that an
+// empty sequence is written as [].
+func writeJSONArray[T any](w io.Writer, seq iter.Seq2[T, error]) error {
+	sep := "[\n  "
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		sep = ",\n  "
+	}
+	if sep == "[\n  " {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
 func parseTimeFlag(s string) (time.Time, error) {
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
 		return t, nil
@@ -35,6 +138,11 @@ func parseTimeFlag(s string) (time.Time, error) {
 }
 
 func formatEventDetail(ev model.Event) string {
+	if ev.Undo {
+		undone := ev
+		undone.Undo = false
+		return "undo " + formatEventDetail(undone)
+	}
 	switch ev.Op {
 	case "status":
 		return fmt.Sprintf("status: %s → %s", ev.From, ev.To)
@@ -49,6 +157,12 @@ func formatEventDetail(ev model.Event) string {
 			return fmt.Sprintf("comment: %s", text)
 		}
 		return "comment"
+	case "uncomment":
+		text := ev.Text
+		if len(text) > 60 {
+			text = text[:57] + "..."
+		}
+		return fmt.Sprintf("remove comment: %s", text)
 	case "link":
 		return fmt.Sprintf("link: parent=%s", ev.To)
 	case "unlink":
@@ -58,6 +172,12 @@ func formatEventDetail(ev model.Event) string {
 	}
 }
 
+// formatEventChanges renders the before/after values of an edit event as
+// indented diff lines; see tracker.RenderChanges.
+func formatEventChanges(ev model.Event) []string {
+	return tracker.RenderChanges(ev.Changes, func(_ byte, s string) string { return s })
+}
+
 func resolveID(t *tracker.Tracker, prefix string) (string, error) {
 	id, err := t.ResolvePrefix(prefix)
 	if err != nil {
@@ -68,31 +188,26 @@ func resolveID(t *tracker.Tracker, prefix string) (string, error) {
 
 // shortID returns the minimum unique prefix for a full issue ID.
 func shortID(t *tracker.Tracker, id string) string {
-	issues, err := t.ListIssues()
+	ids, err := t.Store.ListIssueIDs()
 	if err != nil {
 		return id
 	}
-	ids := make([]string, len(issues))
-	for i, issue := range issues {
-		ids[i] = issue.ID
-	}
 	return tracker.MinPrefix(id, ids)
 }
 
 func completeIssueIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	wd, err := os.Getwd()
-	if err != nil {
+	// Completion doesn't run PersistentPreRunE, which loads cfg.
+	var err error
+	if cfg, err = config.Load(); err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	entries, err := os.ReadDir(filepath.Join(wd, ".work", "issues"))
+	t, err := loadTracker()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	var ids []string
-	for _, e := range entries {
-		if e.IsDir() {
-			ids = append(ids, e.Name())
-		}
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 	return ids, cobra.ShellCompDirectiveNoFileComp
 }
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"time"
 
@@ -30,32 +32,18 @@ limited to 20).`,
 			return err
 		}
 
-		all, err := t.LoadAllEvents()
-		if err != nil {
-			return err
-		}
-
+		var validIDs map[string]bool
 		if historyLabel != "" {
-			issues, err := t.ListIssues()
+			issues, err := t.ListSummaries()
 			if err != nil {
 				return err
 			}
-			validIDs := make(map[string]bool)
+			validIDs = make(map[string]bool)
 			for _, issue := range issues {
-				for _, l := range issue.Labels {
-					if l == historyLabel {
-						validIDs[issue.ID] = true
-						break
-					}
+				if slices.Contains(issue.Labels, historyLabel) {
+					validIDs[issue.ID] = true
 				}
 			}
-			var filtered []tracker.EventWithIssue
-			for _, ev := range all {
-				if validIDs[ev.IssueID] {
-					filtered = append(filtered, ev)
-				}
-			}
-			all = filtered
 		}
 
 		var since, until time.Time
@@ -71,31 +59,54 @@ limited to 20).`,
 				return err
 			}
 		}
-		all = tracker.FilterEventsWithIssueByTime(all, since, until)
-
-		sort.Slice(all, func(i, j int) bool {
-			return all[i].Timestamp.After(all[j].Timestamp)
-		})
-
-		if len(all) == 0 {
-			fmt.Println("No events")
-			return nil
-		}
 
 		limit := historyLast
 		if limit <= 0 {
 			limit = 20
 		}
-		if len(all) < limit {
-			limit = len(all)
+		newestFirst := func(all []tracker.EventWithIssue) {
+			sort.Slice(all, func(i, j int) bool {
+				return all[i].Timestamp.After(all[j].Timestamp)
+			})
+		}
+
+		// Output is newest first, so every history must be read before
+		// printing; stream the events and keep only the newest limit of
+		// them rather than holding the whole tree in memory.
+		var all []tracker.EventWithIssue
+		seen := make(map[string]bool)
+		for ev, err := range t.AllEvents() {
+			if err != nil {
+				return err
+			}
+			if validIDs != nil && !validIDs[ev.IssueID] {
+				continue
+			}
+			if !since.IsZero() && ev.Timestamp.Before(since) {
+				continue
+			}
+			if !until.IsZero() && !ev.Timestamp.Before(until) {
+				continue
+			}
+			seen[ev.IssueID] = true
+			all = append(all, ev)
+			if len(all) >= 2*limit {
+				newestFirst(all)
+				all = all[:limit]
+			}
+		}
+		newestFirst(all)
+
+		if len(all) == 0 {
+			fmt.Println("No events")
+			return nil
 		}
-		ids := make([]string, len(all))
-		for i, ev := range all {
-			ids[i] = ev.IssueID
+		if len(all) > limit {
+			all = all[:limit]
 		}
-		short := tracker.MinPrefixes(ids)
+		short := tracker.MinPrefixes(slices.Collect(maps.Keys(seen)))
 
-		for _, ev := range all[:limit] {
+		for _, ev := range all {
 			fmt.Printf("%s  %s  %s  (%s)\n",
 				ev.Timestamp.Format("2006-01-02 15:04:05"),
 				short[ev.IssueID],
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 
 	"github.com/jfmyers9/work/internal/tracker"
@@ -22,10 +23,13 @@ Also creates a Claude Code SessionStart hook so that Claude
 auto-discovers the work CLI.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, err := os.Getwd()
+		wd, err := startDir()
 		if err != nil {
 			return err
 		}
+		if root := workDirOverride(wd); root != "" {
+			wd = root
+		}
 		_, err = tracker.Init(wd)
 		if err != nil {
 			return err
@@ -34,6 +38,11 @@ auto-discovers the work CLI.`,
 		fmt.Println("")
 		fmt.Println("For compact git diffs, run:")
 		fmt.Println("  git config diff.work.textconv 'jq -c .'")
+		if err := registerMergeDriver(wd); err != nil {
+			fmt.Println("")
+			fmt.Println("To merge .work/ changes across branches, run:")
+			fmt.Printf("  git config merge.work.driver '%s'\n", mergeDriverCommand)
+		}
 
 		settingsFile := "settings.json"
 		if initLocal {
@@ -91,6 +100,23 @@ auto-discovers the work CLI.`,
 	},
 }
 
+// registerMergeDriver defines the "work" merge driver referenced by
+// .gitattributes in the repository's local git config.
+func registerMergeDriver(dir string) error {
+	settings := [][2]string{
+		{"merge.work.name", "work issue tracker merge"},
+		{"merge.work.driver", mergeDriverCommand},
+	}
+	for _, kv := range settings {
+		c := exec.Command("git", "config", kv[0], kv[1])
+		c.Dir = dir
+		if err := c.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	initCmd.Flags().BoolVar(&initLocal, "local", false, "Write hook to settings.local.json instead of settings.json")
 	rootCmd.AddCommand(initCmd)
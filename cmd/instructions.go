@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/jfmyers9/work/internal/model"
 	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
@@ -88,19 +88,24 @@ Include .work/ changes in the same commit as related code changes.`)
 			return nil
 		}
 
-		wd, err := os.Getwd()
+		root, err := trackerRoot()
 		if err != nil {
 			return nil
 		}
-		t, err := tracker.Load(wd)
+		t, err := tracker.Load(root)
 		if err != nil {
 			return nil
 		}
-		allIssues, err := t.ListIssues()
+		allIssues, err := t.ListSummaries()
 		if err != nil {
 			return nil
 		}
-		active := tracker.FilterIssues(allIssues, tracker.FilterOptions{Status: "active"})
+		var active []model.Issue
+		for _, issue := range allIssues {
+			if t.Config.Category(issue.Status) == model.CategoryInProgress {
+				active = append(active, issue)
+			}
+		}
 		if len(active) == 0 {
 			return nil
 		}
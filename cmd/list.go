@@ -3,7 +3,11 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/jfmyers9/work/internal/model"
 	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
@@ -20,21 +24,37 @@ var (
 	listFormat   string
 	listLast     int
 	listAll      bool
+	listAt       string
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List issues",
-	Long:  `List issues with optional filtering and sorting.`,
+	Long: `List issues with optional filtering and sorting. With --at, list
+issues as they were at that time, reconstructed from their history.`,
 	Example: `  work list --status active
-  work list --label backend --sort priority`,
+  work list --label backend --sort priority
+  work list --at 2026-01-15T17:00:00Z --status active`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		t, err := loadTracker()
 		if err != nil {
 			return err
 		}
-		allIssues, err := t.ListIssues()
+		var allIssues []model.Issue
+		if listAt != "" {
+			var at time.Time
+			if at, err = parseTimeFlag(listAt); err != nil {
+				return err
+			}
+			var incomplete []string
+			allIssues, incomplete, err = t.IssuesAt(at)
+			for _, id := range incomplete {
+				fmt.Fprintf(os.Stderr, "warning: %s omitted: its history does not go back to %s\n", shortID(t, id), listAt)
+			}
+		} else {
+			allIssues, err = t.ListSummaries()
+		}
 		if err != nil {
 			return err
 		}
@@ -46,7 +66,7 @@ var listCmd = &cobra.Command{
 			Type:     listType,
 		}
 		if !listAll && listStatus == "" {
-			opts.ExcludeStatuses = []string{"done", "cancelled"}
+			opts.ExcludeStatuses = t.Config.TerminalStates()
 		}
 		if cmd.Flags().Changed("priority") {
 			opts.Priority = listPriority
@@ -70,6 +90,15 @@ var listCmd = &cobra.Command{
 		}
 
 		if listFormat == "json" {
+			if listAt == "" {
+				// Summaries omit descriptions and comments; load the
+				// full documents for the issues being printed.
+				for i, issue := range issues {
+					if issues[i], err = t.LoadIssue(issue.ID); err != nil {
+						return err
+					}
+				}
+			}
 			data, err := json.MarshalIndent(issues, "", "  ")
 			if err != nil {
 				return err
@@ -93,6 +122,7 @@ var listCmd = &cobra.Command{
 
 		if len(issues) == 0 {
 			fmt.Println("No issues found")
+			printWIPLoad(t.Config, allIssues)
 			return nil
 		}
 
@@ -101,7 +131,7 @@ var listCmd = &cobra.Command{
 			if issue.ParentID != "" {
 				c := childCounts[issue.ParentID]
 				c.total++
-				if issue.Status == "done" || issue.Status == "cancelled" {
+				if t.Config.IsTerminal(issue.Status) {
 					c.done++
 				}
 				childCounts[issue.ParentID] = c
@@ -120,12 +150,26 @@ var listCmd = &cobra.Command{
 			}
 			fmt.Printf("%-8s %-10s %-10s %-8d %-10s %s\n", short[issue.ID], issue.Status, issue.Type, issue.Priority, children, title)
 		}
+		printWIPLoad(t.Config, allIssues)
 		return nil
 	},
 }
 
+// printWIPLoad prints the load on states with WIP limits, if any.
+func printWIPLoad(c model.Config, issues []model.Issue) {
+	load := tracker.WIPLoad(c, issues)
+	if len(load) == 0 {
+		return
+	}
+	parts := make([]string, len(load))
+	for i, u := range load {
+		parts[i] = u.String()
+	}
+	fmt.Printf("\nWIP: %s\n", strings.Join(parts, "  "))
+}
+
 func init() {
-	listCmd.Flags().StringVar(&listStatus, "status", "", "Filter by status (open|active|review|done|cancelled)")
+	listCmd.Flags().StringVar(&listStatus, "status", "", "Filter by status (e.g. open|active|review|done|cancelled)")
 	listCmd.Flags().StringVar(&listLabel, "label", "", "Filter by label")
 	listCmd.Flags().StringVar(&listAssignee, "assignee", "", "Filter by assignee")
 	listCmd.Flags().StringVar(&listType, "type", "", "Filter by type")
@@ -135,6 +179,7 @@ func init() {
 	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort by field (title|priority|status|created|updated)")
 	listCmd.Flags().StringVar(&listFormat, "format", "", "Output format (json|short)")
 	listCmd.Flags().IntVar(&listLast, "last", 0, "Show only the last N issues")
-	listCmd.Flags().BoolVar(&listAll, "all", false, "Show all issues including done/cancelled")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "Show all issues including those in terminal states")
+	listCmd.Flags().StringVar(&listAt, "at", "", "List issues as of a time (YYYY-MM-DD or RFC3339)")
 	rootCmd.AddCommand(listCmd)
 }
@@ -60,6 +60,9 @@ var logCmd = &cobra.Command{
 				ev.Timestamp.Format("2006-01-02 15:04:05"),
 				formatEventDetail(ev),
 				ev.By)
+			for _, line := range formatEventChanges(ev) {
+				fmt.Println(line)
+			}
 		}
 		return nil
 	},
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jfmyers9/work/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+const mergeDriverCommand = "work merge-driver %O %A %B"
+
+var mergeDriverCmd = &cobra.Command{
+	Use:   "merge-driver <base> <ours> <theirs>",
+	Short: "Git merge driver for .work/ files",
+	Long: `Merge two versions of an issue.json, history.jsonl or log.jsonl file.
+Invoked by git through the "work" merge driver that work init registers;
+the merged result is written over <ours>.`,
+	Args:   cobra.ExactArgs(3),
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var versions [3][]byte
+		for i, path := range args {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			versions[i] = data
+		}
+		merged, err := tracker.MergeFile(versions[0], versions[1], versions[2])
+		if err != nil {
+			return fmt.Errorf("merging %s: %w", args[1], err)
+		}
+		return os.WriteFile(args[1], merged, 0o644)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeDriverCmd)
+}
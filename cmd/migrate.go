@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jfmyers9/work/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var migrateDryRun bool
+
+// migrating suppresses loadTracker's "run work migrate" hint while the
+// migrate command itself is running.
+var migrating bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade .work/ to the current schema version",
+	Long: `Apply every pending schema migration to .work/, in order. The
+schema version is recorded in config.json after each step, so an
+interrupted migration can simply be re-run. With --dry-run, list what
+each pending migration would change without writing anything.`,
+	Example: `  work migrate --dry-run
+  work migrate`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		migrating = true
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		from := t.Config.SchemaVersion
+		results, err := t.Migrate(migrateDryRun)
+		for _, r := range results {
+			fmt.Printf("%d: %s\n", r.Version, r.Description)
+			for _, a := range r.Actions {
+				fmt.Printf("  %s\n", a)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(results) == 0:
+			fmt.Printf("Already at schema version %d\n", tracker.SchemaVersion)
+		case migrateDryRun:
+			fmt.Printf("\nWould migrate from schema version %d to %d\n", from, tracker.SchemaVersion)
+		default:
+			fmt.Printf("\nMigrated from schema version %d to %d\n", from, tracker.SchemaVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "List pending changes without applying them")
+	rootCmd.AddCommand(migrateCmd)
+}
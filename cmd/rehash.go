@@ -3,51 +3,59 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
+var (
+	rehashScheme string
+	rehashPrefix string
+)
+
 var rehashCmd = &cobra.Command{
 	Use:   "rehash",
-	Short: "Upgrade old hex IDs to Crockford Base32",
-	Long: `Re-generate IDs for issues that use the old hex encoding.
-New Crockford Base32 IDs are shorter to type and have better
-prefix diversity. All references (parent links, log entries)
-are updated automatically.`,
+	Short: "Re-generate IDs that don't fit the configured scheme",
+	Long: `Re-generate IDs for issues whose ID doesn't fit the configured
+ID scheme, such as issues that still use the old hex encoding.
+With --scheme, first switch config.json to that scheme (random,
+sequential, prefixed or ulid) and migrate every issue to it; counting
+schemes number issues in creation order. All references (parent links,
+log entries) are updated automatically.`,
+	Example: `  work rehash
+  work rehash --scheme prefixed --prefix API
+  work rehash --scheme ulid`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		t, err := loadTracker()
 		if err != nil {
 			return err
 		}
+		scheme, prefix := t.Config.IDScheme, t.Config.IDPrefix
+		if cmd.Flags().Changed("scheme") {
+			scheme = rehashScheme
+		}
+		if cmd.Flags().Changed("prefix") {
+			prefix = rehashPrefix
+		}
 
-		issues, err := t.ListIssues()
+		renames, err := t.MigrateIDs(scheme, prefix)
+		for _, r := range renames {
+			fmt.Printf("%s → %s  %s\n", r.OldID, r.NewID, r.Title)
+		}
 		if err != nil {
 			return err
 		}
 
-		var rehashed int
-		for _, issue := range issues {
-			if !tracker.IsHexID(issue.ID) {
-				continue
-			}
-			newID, err := t.RehashIssue(issue.ID)
-			if err != nil {
-				return fmt.Errorf("rehashing %s: %w", issue.ID, err)
-			}
-			fmt.Printf("%s → %s  %s\n", issue.ID, newID, issue.Title)
-			rehashed++
-		}
-
-		if rehashed == 0 {
-			fmt.Println("No hex IDs to rehash")
+		if len(renames) == 0 {
+			fmt.Println("No IDs to rehash")
 		} else {
-			fmt.Printf("\nRehashed %d issues\n", rehashed)
+			fmt.Printf("\nRehashed %d issues\n", len(renames))
 		}
 		return nil
 	},
 }
 
 func init() {
+	rehashCmd.Flags().StringVar(&rehashScheme, "scheme", "", "Switch to this ID scheme (random|sequential|prefixed|ulid)")
+	rehashCmd.Flags().StringVar(&rehashPrefix, "prefix", "", "Project key for the prefixed scheme, e.g. API")
 	rootCmd.AddCommand(rehashCmd)
 }
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the issue summary index",
+	Long: `Discard and rebuild .work/cache/index.json, the git-ignored index
+that list and show use to avoid re-reading every issue. The index is
+kept up to date automatically; reindex is only needed if it is suspected
+to be stale, e.g. after restoring files with preserved timestamps.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		n, err := t.Reindex()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Indexed %d issue(s)\n", n)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
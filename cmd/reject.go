@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -29,18 +30,24 @@ var rejectCmd = &cobra.Command{
 			return err
 		}
 		oldStatus := old.Status
+		newStatus := tracker.ShortcutState(t.Config, old, "active")
+		t.IgnoreWIPLimits, _ = cmd.Flags().GetBool("force")
+		if err := meetGuards(cmd, t, old, newStatus); err != nil {
+			return err
+		}
 
-		if _, err := t.SetStatus(id, "active", cfg.User); err != nil {
+		if _, err := t.SetStatus(id, newStatus, cfg.User); err != nil {
 			return err
 		}
 		if _, err := t.AddComment(id, "Rejected: "+reason, cfg.User); err != nil {
 			return err
 		}
-		fmt.Printf("%s: %s → active (rejected: %s)\n", shortID(t, id), oldStatus, reason)
+		fmt.Printf("%s: %s → %s (rejected: %s)\n", shortID(t, id), oldStatus, newStatus, reason)
 		return nil
 	},
 }
 
 func init() {
+	rejectCmd.Flags().Bool("force", false, "Ignore WIP limits")
 	rootCmd.AddCommand(rejectCmd)
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jfmyers9/work/internal/model"
+	"github.com/jfmyers9/work/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var replayWrite bool
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <id>",
+	Short: "Rebuild or verify an issue from its history",
+	Long: `Replay an issue's history.jsonl and compare the result with its
+issue.json. Differences point to hand edits or a bad merge. With --write,
+issue.json is replaced by the replayed state. Exits non-zero if the two
+differ and --write was not given.`,
+	Example: `  work replay abc123
+  work replay abc --write`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIssueIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		id, err := resolveID(t, args[0])
+		if err != nil {
+			return err
+		}
+
+		if replayWrite {
+			mismatches, err := t.RebuildIssue(id)
+			if err != nil {
+				return err
+			}
+			if len(mismatches) == 0 {
+				fmt.Printf("%s already matches its history\n", shortID(t, id))
+				return nil
+			}
+			fmt.Printf("Rebuilt %s from history\n", shortID(t, id))
+			printMismatches(mismatches)
+			return nil
+		}
+
+		stored, err := t.LoadIssue(id)
+		if err != nil {
+			return err
+		}
+		replayed, err := t.ReplayIssue(id)
+		if err != nil {
+			return err
+		}
+		mismatches, err := tracker.ReplayMismatches(stored, replayed)
+		if err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			fmt.Printf("%s matches its history\n", shortID(t, id))
+			return nil
+		}
+		fmt.Printf("%s differs from its history (issue.json → replayed):\n", shortID(t, id))
+		printMismatches(mismatches)
+		return fmt.Errorf("issue.json does not match history; run with --write to rebuild it")
+	},
+}
+
+func printMismatches(mismatches []model.FieldChange) {
+	for _, line := range formatEventChanges(model.Event{Changes: mismatches}) {
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayWrite, "write", false, "Replace issue.json with the replayed state")
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Bring an archived issue back from .work/archive/",
+	Long: `Move an issue archived by 'work gc --archive' back into .work/issues/
+with its full history. A "restore" event is added to the history and
+the issue's entry in the completion log is updated to match. The ID
+may be any unique prefix of an archived issue's ID.`,
+	Example: `  work restore abc123
+  work restore abc`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		issue, err := t.RestoreIssue(args[0], cfg.User)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s  %s  %s\n", shortID(t, issue.ID), issue.Status, issue.Title)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
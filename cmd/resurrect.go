@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var resurrectCmd = &cobra.Command{
+	Use:   "resurrect <id>",
+	Short: "Recover a purged issue from git history",
+	Long: `Recover an issue deleted by 'work gc' from git history. The issue and
+its history are restored from the last commit in which
+.work/issues/<id>/issue.json existed, and a "restore" event is added.
+The ID may be any unique prefix of a purged issue in the completion log.
+For issues archived with 'work gc --archive', use 'work restore'.`,
+	Example: `  work resurrect abc123
+  work resurrect abc`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		issue, commit, err := t.Resurrect(args[0], cfg.User)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Resurrected %s from %s  %s  %s\n", shortID(t, issue.ID), commit[:7], issue.Status, issue.Title)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resurrectCmd)
+}
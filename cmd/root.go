@@ -11,6 +11,13 @@ import (
 
 var cfg config.Config
 
+// chdir is the global -C flag: run as if work was started in that
+// directory.
+var chdir string
+
+// noAutomation is the global --no-automation flag.
+var noAutomation bool
+
 var rootCmd = &cobra.Command{
 	Use:           "work",
 	Short:         "A lightweight, git-friendly issue tracker",
@@ -25,9 +32,15 @@ var rootCmd = &cobra.Command{
 		cfg, err = config.Load()
 		return err
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		reportWarnings()
+	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&chdir, "chdir", "C", "", "Run as if work was started in `path`")
+	rootCmd.PersistentFlags().BoolVar(&noAutomation, "no-automation", false, "Don't apply automation rules from config.json")
+
 	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		printHelp(os.Stderr)
 		return fmt.Errorf("unknown command")
@@ -52,7 +65,7 @@ func printHelp(w *os.File) {
 	_, _ = fmt.Fprintln(w)
 	_, _ = fmt.Fprintln(w, "Commands:")
 	for _, c := range rootCmd.Commands() {
-		if c.Name() == "completion" || c.Name() == "help" {
+		if c.Hidden || c.Name() == "completion" || c.Name() == "help" {
 			continue
 		}
 		_, _ = fmt.Fprintf(w, "  %-14s%s\n", c.Name(), c.Short)
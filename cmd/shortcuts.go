@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jfmyers9/work/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -30,13 +31,18 @@ func newShortcutCmd(use, short, long, example, targetStatus string, withNoCompac
 				return err
 			}
 			oldStatus := old.Status
+			newStatus := tracker.ShortcutState(t.Config, old, targetStatus)
+			t.IgnoreWIPLimits, _ = cmd.Flags().GetBool("force")
+			if err := meetGuards(cmd, t, old, newStatus); err != nil {
+				return err
+			}
 
-			if _, err := t.SetStatus(id, targetStatus, cfg.User); err != nil {
+			if _, err := t.SetStatus(id, newStatus, cfg.User); err != nil {
 				return err
 			}
-			fmt.Printf("%s: %s → %s\n", shortID(t, id), oldStatus, targetStatus)
+			fmt.Printf("%s: %s → %s\n", shortID(t, id), oldStatus, newStatus)
 
-			if targetStatus == "done" || targetStatus == "cancelled" {
+			if t.Config.IsTerminal(newStatus) {
 				noCompact, _ := cmd.Flags().GetBool("no-compact")
 				if !noCompact {
 					if err := t.CompactIssue(id); err != nil {
@@ -50,6 +56,7 @@ func newShortcutCmd(use, short, long, example, targetStatus string, withNoCompac
 	if withNoCompact {
 		cmd.Flags().Bool("no-compact", false, "Skip auto-compaction")
 	}
+	addTransitionFlags(cmd)
 	return cmd
 }
 
@@ -11,15 +11,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var showFormat string
+var (
+	showFormat string
+	showAt     string
+)
 
 var showCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show issue details",
 	Long: `Display full details for a single issue, including comments
-and child issues.`,
+and child issues. With --at, show the issue as it was at that time,
+reconstructed from its history.`,
 	Example: `  work show abc123
-  work show abc --format=json`,
+  work show abc --format=json
+  work show abc --at 2026-01-15`,
 	Args:              cobra.ExactArgs(1),
 	ValidArgsFunction: completeIssueIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -35,13 +40,27 @@ and child issues.`,
 			if logErr == nil {
 				for _, e := range entries {
 					if strings.HasPrefix(e.ID, prefix) {
-						return fmt.Errorf("issue %s was purged (completed %s)\nUse 'work completed' to view completion history", e.ID, e.Closed.Format("2006-01-02"))
+						if e.Archive != "" {
+							return fmt.Errorf("issue %s was archived (completed %s)\nUse 'work restore %s' to bring it back", e.ID, e.Closed.Format("2006-01-02"), e.ID)
+						}
+						return fmt.Errorf("issue %s was purged (completed %s)\nUse 'work resurrect %s' to recover it from git history", e.ID, e.Closed.Format("2006-01-02"), e.ID)
 					}
 				}
 			}
 			return err
 		}
-		issue, err := t.LoadIssue(id)
+		var at time.Time
+		if showAt != "" {
+			if at, err = parseTimeFlag(showAt); err != nil {
+				return err
+			}
+		}
+		var issue model.Issue
+		if at.IsZero() {
+			issue, err = t.LoadIssue(id)
+		} else {
+			issue, err = t.IssueAt(id, at)
+		}
 		if err != nil {
 			return err
 		}
@@ -55,7 +74,12 @@ and child issues.`,
 			return nil
 		}
 
-		allIssues, err := t.ListIssues()
+		var allIssues []model.Issue
+		if at.IsZero() {
+			allIssues, err = t.ListSummaries()
+		} else {
+			allIssues, _, err = t.IssuesAt(at)
+		}
 		allIDs := make([]string, 0)
 		if err == nil {
 			for _, i := range allIssues {
@@ -94,7 +118,7 @@ and child issues.`,
 			if len(children) > 0 {
 				done := 0
 				for _, c := range children {
-					if c.Status == "done" || c.Status == "cancelled" {
+					if t.Config.IsTerminal(c.Status) {
 						done++
 					}
 				}
@@ -120,5 +144,6 @@ and child issues.`,
 
 func init() {
 	showCmd.Flags().StringVar(&showFormat, "format", "", "Output format (json)")
+	showCmd.Flags().StringVar(&showAt, "at", "", "Show the issue as of a time (YYYY-MM-DD or RFC3339)")
 	rootCmd.AddCommand(showCmd)
 }
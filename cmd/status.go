@@ -13,9 +13,17 @@ var statusCmd = &cobra.Command{
 	Use:   "status <id> <state>",
 	Short: "Change issue status",
 	Long: `Set an issue's status to any valid state.
-Valid states: open, active, review, done, cancelled.`,
+States are defined in .work/config.json; by default they are
+open, active, review, done and cancelled. Entering a terminal
+state (done and cancelled by default) auto-compacts the issue.
+
+States can have guards in config.json, such as requiring an assignee
+or a reason comment. Missing values come from --assignee, --priority
+and --reason, or are asked for when run from a terminal. Moving into
+a state at its WIP limit needs --force.`,
 	Example: `  work status abc123 active
-  work status abc done`,
+  work status abc done
+  work status abc cancelled --reason "duplicate of def"`,
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: completeIssueIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,13 +42,17 @@ Valid states: open, active, review, done, cancelled.`,
 			return err
 		}
 		oldStatus := old.Status
+		t.IgnoreWIPLimits, _ = cmd.Flags().GetBool("force")
+		if err := meetGuards(cmd, t, old, newStatus); err != nil {
+			return err
+		}
 
 		if _, err := t.SetStatus(id, newStatus, cfg.User); err != nil {
 			return err
 		}
 		fmt.Printf("%s: %s → %s\n", shortID(t, id), oldStatus, newStatus)
 
-		if newStatus == "done" || newStatus == "cancelled" {
+		if t.Config.IsTerminal(newStatus) {
 			if !statusNoCompact {
 				if err := t.CompactIssue(id); err != nil {
 					fmt.Fprintf(os.Stderr, "warning: compact failed: %v\n", err)
@@ -53,5 +65,6 @@ Valid states: open, active, review, done, cancelled.`,
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusNoCompact, "no-compact", false, "Skip auto-compaction")
+	addTransitionFlags(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
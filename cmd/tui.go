@@ -9,7 +9,11 @@ var tuiCmd = &cobra.Command{
 	Use:   "tui",
 	Short: "Interactive terminal UI",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return tui.Run()
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		return tui.Run(t, cfg.User, cfg.Editor)
 	},
 }
 
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var undoSteps int
+
+var undoCmd = &cobra.Command{
+	Use:   "undo <id>",
+	Short: "Revert the last changes to an issue",
+	Long: `Revert the most recent status, edit, link/unlink and comment changes
+to an issue, as recorded in its history. Each reverted change is recorded
+as a new "undo" event; history is never deleted. Changes made before the
+issue was compacted cannot be undone.`,
+	Example: `  work undo abc123
+  work undo abc --steps 3`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIssueIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTracker()
+		if err != nil {
+			return err
+		}
+		id, err := resolveID(t, args[0])
+		if err != nil {
+			return err
+		}
+
+		undone, err := t.UndoIssue(id, undoSteps, cfg.User)
+		if err != nil {
+			return err
+		}
+		for _, ev := range undone {
+			fmt.Printf("%s: %s\n", shortID(t, id), formatEventDetail(ev))
+		}
+		return nil
+	},
+}
+
+func init() {
+	undoCmd.Flags().IntVar(&undoSteps, "steps", 1, "Number of changes to revert")
+	rootCmd.AddCommand(undoCmd)
+}
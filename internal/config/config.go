@@ -5,14 +5,17 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type Config struct {
-	User   string `env:"WORK_USER"`
-	Editor string `env:"EDITOR"`
-	Visual string `env:"VISUAL"`
+	User        string        `env:"WORK_USER"`
+	Editor      string        `env:"EDITOR"`
+	Visual      string        `env:"VISUAL"`
+	LockTimeout time.Duration `env:"WORK_LOCK_TIMEOUT" envDefault:"10s"`
+	Dir         string        `env:"WORK_DIR"`
 }
 
 func Load() (Config, error) {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestLoad_AllVarsSet(t *testing.T) {
@@ -76,3 +77,38 @@ func TestLoad_EDITORTakesPrecedenceOverVISUAL(t *testing.T) {
 		t.Errorf("editor: got %q, want nano (EDITOR should take precedence)", cfg.Editor)
 	}
 }
+
+func TestLoad_LockTimeout(t *testing.T) {
+	t.Setenv("WORK_USER", "alice")
+	t.Setenv("WORK_LOCK_TIMEOUT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.LockTimeout != 10*time.Second {
+		t.Errorf("default lock timeout: got %v, want 10s", cfg.LockTimeout)
+	}
+
+	t.Setenv("WORK_LOCK_TIMEOUT", "250ms")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.LockTimeout != 250*time.Millisecond {
+		t.Errorf("lock timeout: got %v, want 250ms", cfg.LockTimeout)
+	}
+}
+
+func TestLoad_Dir(t *testing.T) {
+	t.Setenv("WORK_USER", "alice")
+	t.Setenv("WORK_DIR", "/srv/project")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Dir != "/srv/project" {
+		t.Errorf("dir: got %q, want /srv/project", cfg.Dir)
+	}
+}
@@ -86,3 +86,21 @@ func UnmarshalIssue(text string) (title, description, issueType, assignee string
 	description = strings.TrimSpace(strings.Join(bodyLines, "\n"))
 	return
 }
+
+// ConflictNotice renders '#' comment lines, placed above MarshalIssue
+// output, describing fields someone else changed while the user was
+// editing. saved[i] is the value saved for fields[i].
+func ConflictNotice(fields, saved []string) string {
+	var b strings.Builder
+	b.WriteString("# CONFLICT: this issue was changed while you were editing.\n")
+	b.WriteString("# The fields below show your version. The saved values are:\n")
+	for i, field := range fields {
+		lines := strings.Split(saved[i], "\n")
+		fmt.Fprintf(&b, "#   %s: %s\n", field, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(&b, "#     %s\n", line)
+		}
+	}
+	b.WriteString("# Save as-is to keep your version, or copy the saved values back to keep theirs.\n")
+	return b.String()
+}
@@ -1,7 +1,13 @@
 // Package model defines the core issue, comment, and event types.
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
 
 type Comment struct {
 	Text    string    `json:"text"`
@@ -20,18 +26,37 @@ type Issue struct {
 	ParentID    string    `json:"parent_id,omitempty"`
 	Created     time.Time `json:"created"`
 	Updated     time.Time `json:"updated"`
+	Revision    int       `json:"revision,omitempty"`
 	Description string    `json:"description,omitempty"`
 	Comments    []Comment `json:"comments,omitempty"`
 }
 
 type Event struct {
-	Timestamp time.Time `json:"ts"`
-	Op        string    `json:"op"`
-	Fields    []string  `json:"fields,omitempty"`
-	From      string    `json:"from,omitempty"`
-	To        string    `json:"to,omitempty"`
-	Text      string    `json:"text,omitempty"`
-	By        string    `json:"by,omitempty"`
+	Timestamp time.Time     `json:"ts"`
+	Op        string        `json:"op"`
+	Fields    []string      `json:"fields,omitempty"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+	From      string        `json:"from,omitempty"`
+	To        string        `json:"to,omitempty"`
+	Text      string        `json:"text,omitempty"`
+	By        string        `json:"by,omitempty"`
+
+	// Issue holds the initial state of the issue on "create" events.
+	Issue *Issue `json:"issue,omitempty"`
+	// Undo marks a compensating event appended by `work undo`; it reverts
+	// the latest earlier event not already undone.
+	Undo bool `json:"undo,omitempty"`
+	// Compacted marks the last event kept when history was compacted.
+	// Events before it were discarded.
+	Compacted bool `json:"compacted,omitempty"`
+}
+
+// FieldChange records the JSON value of one issue field before and after
+// an edit.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
 }
 
 type Config struct {
@@ -40,6 +65,259 @@ type Config struct {
 	Types        []string            `json:"types"`
 	DefaultType  string              `json:"default_type"`
 	IDLength     int                 `json:"id_length"`
+	// IDScheme selects how new IDs are generated: "random" (the
+	// default when empty), "sequential", "prefixed" or "ulid".
+	IDScheme string `json:"id_scheme,omitempty"`
+	// IDPrefix is the project key for the prefixed scheme, e.g. "API"
+	// for IDs like API-42.
+	IDPrefix string `json:"id_prefix,omitempty"`
+	// SchemaVersion is the layout version of the .work/ tree. Trees
+	// written before versioning was introduced read as 0.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// States declares what each workflow state means. States missing
+	// here behave like the built-in state of the same name, if any.
+	States map[string]StateConfig `json:"states,omitempty"`
+	// Workflows gives issue types their own transitions, keyed by
+	// type. Types missing here follow Transitions and DefaultState.
+	Workflows map[string]Workflow `json:"workflows,omitempty"`
+	// Guards lists, by state, the conditions an issue must meet to
+	// enter that state.
+	Guards map[string][]Guard `json:"guards,omitempty"`
+	// WIPLimits caps, by state, how many issues may be in that state
+	// at once.
+	WIPLimits map[string]WIPLimit `json:"wip_limits,omitempty"`
+	// Automation lists rules applied after each change, in order.
+	Automation []Rule `json:"automation,omitempty"`
+}
+
+// UnmarshalJSON reads a config. Older trackers wrote a plain list of
+// state names under "states"; those states are declared with the
+// meaning they would have had anyway.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+	var raw struct {
+		config
+		States json.RawMessage `json:"states,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Config(raw.config)
+	if len(raw.States) == 0 {
+		return nil
+	}
+	err := json.Unmarshal(raw.States, &c.States)
+	if err == nil {
+		return nil
+	}
+	var names []string
+	if json.Unmarshal(raw.States, &names) != nil {
+		return err
+	}
+	c.States = make(map[string]StateConfig, len(names))
+	for _, name := range names {
+		c.States[name] = c.State(name)
+	}
+	return nil
+}
+
+// Automation triggers.
+const (
+	TriggerEnters           = "enters"
+	TriggerChildrenTerminal = "children-terminal"
+	TriggerReopened         = "reopened"
+)
+
+// Triggers lists the known automation triggers.
+var Triggers = []string{TriggerEnters, TriggerChildrenTerminal, TriggerReopened}
+
+// Rule is an automation rule. When its trigger fires for an issue that
+// passes its filters, its actions are applied to that issue.
+type Rule struct {
+	Name string `json:"name,omitempty"`
+	// When is one of Triggers: an issue entering State (including being
+	// created in it), the last open child of an issue reaching a
+	// terminal state or being unlinked from it (the rule then acts on
+	// the parent), or an issue leaving a terminal state for the
+	// Count-th time or later.
+	When  string `json:"when"`
+	State string `json:"state,omitempty"`
+	Count int    `json:"count,omitempty"`
+	// Type and Unassigned restrict the rule to issues of that type, or
+	// without an assignee.
+	Type       string `json:"type,omitempty"`
+	Unassigned bool   `json:"unassigned,omitempty"`
+	// The actions: move the issue to SetStatus, assign it to whoever
+	// made the triggering change, add a label.
+	SetStatus   string `json:"set_status,omitempty"`
+	AssignActor bool   `json:"assign_actor,omitempty"`
+	AddLabel    string `json:"add_label,omitempty"`
+}
+
+// WIPLimit caps the issues in a state. Zero means no cap.
+type WIPLimit struct {
+	Total       int `json:"total,omitempty"`
+	PerAssignee int `json:"per_assignee,omitempty"`
+}
+
+// Guard requirements.
+const (
+	RequireAssignee         = "assignee"
+	RequireChildrenTerminal = "children-terminal"
+	RequireComment          = "comment"
+	RequirePriority         = "priority"
+)
+
+// Requirements lists the known guard requirements.
+var Requirements = []string{RequireAssignee, RequireChildrenTerminal, RequireComment, RequirePriority}
+
+// Guard is a condition on entering a state.
+type Guard struct {
+	// Require is one of Requirements: an assignee, every child in a
+	// terminal state, a comment added since the last status change, or
+	// a priority between Min and Max (unbounded above when Max is 0).
+	Require string `json:"require"`
+	Min     int    `json:"min,omitempty"`
+	Max     int    `json:"max,omitempty"`
+}
+
+// Workflow is the set of states and transitions an issue type follows.
+type Workflow struct {
+	Transitions map[string][]string `json:"transitions"`
+	// DefaultState is the state new issues of the type start in. Empty
+	// means the config's DefaultState.
+	DefaultState string `json:"default_state,omitempty"`
+}
+
+// Workflow returns the workflow issues of issueType follow: its entry
+// in Workflows, else the global Transitions.
+func (c Config) Workflow(issueType string) Workflow {
+	w, ok := c.Workflows[issueType]
+	if !ok {
+		w = Workflow{Transitions: c.Transitions}
+	}
+	if w.DefaultState == "" {
+		w.DefaultState = c.DefaultState
+	}
+	return w
+}
+
+// HasState reports whether the workflow lists transitions out of state.
+// Issues in any other state are stuck.
+func (w Workflow) HasState(state string) bool {
+	_, ok := w.Transitions[state]
+	return ok
+}
+
+// State categories, in workflow order.
+const (
+	CategoryBacklog    = "backlog"
+	CategoryInProgress = "in-progress"
+	CategoryReview     = "review"
+	CategoryDone       = "done"
+	CategoryCancelled  = "cancelled"
+)
+
+// Categories lists the state categories in workflow order.
+var Categories = []string{CategoryBacklog, CategoryInProgress, CategoryReview, CategoryDone, CategoryCancelled}
+
+// StateConfig describes how a workflow state behaves.
+type StateConfig struct {
+	// Category groups states with the same meaning; one of Categories.
+	Category string `json:"category"`
+	// Terminal marks states that finish an issue. Terminal issues are
+	// hidden from default listings, count as done for their parent,
+	// are compacted on entry and can be purged by gc.
+	Terminal bool `json:"terminal,omitempty"`
+}
+
+// builtinStates gives the meaning of the default states, so that
+// configs written before States existed keep working.
+var builtinStates = map[string]StateConfig{
+	"open":      {Category: CategoryBacklog},
+	"active":    {Category: CategoryInProgress},
+	"review":    {Category: CategoryReview},
+	"done":      {Category: CategoryDone, Terminal: true},
+	"cancelled": {Category: CategoryCancelled, Terminal: true},
+}
+
+// State returns how state behaves: its entry in States, else the
+// built-in state of the same name, else a non-terminal backlog state.
+func (c Config) State(state string) StateConfig {
+	if sc, ok := c.States[state]; ok {
+		return sc
+	}
+	if sc, ok := builtinStates[state]; ok {
+		return sc
+	}
+	return StateConfig{Category: CategoryBacklog}
+}
+
+// IsTerminal reports whether state finishes an issue.
+func (c Config) IsTerminal(state string) bool {
+	return c.State(state).Terminal
+}
+
+// Category returns the category of state.
+func (c Config) Category(state string) string {
+	return c.State(state).Category
+}
+
+// StateNames returns every state named in the config, ordered by
+// category and then by name.
+func (c Config) StateNames() []string {
+	seen := make(map[string]bool)
+	add := func(s string) {
+		if s != "" {
+			seen[s] = true
+		}
+	}
+	add(c.DefaultState)
+	addTransitions := func(transitions map[string][]string) {
+		for from, tos := range transitions {
+			add(from)
+			for _, to := range tos {
+				add(to)
+			}
+		}
+	}
+	addTransitions(c.Transitions)
+	for _, w := range c.Workflows {
+		add(w.DefaultState)
+		addTransitions(w.Transitions)
+	}
+	for s := range c.States {
+		add(s)
+	}
+	names := make([]string, 0, len(seen))
+	for s := range seen {
+		names = append(names, s)
+	}
+	rank := func(s string) int {
+		i := slices.Index(Categories, c.Category(s))
+		if i < 0 {
+			return len(Categories)
+		}
+		return i
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if d := rank(a) - rank(b); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	return names
+}
+
+// TerminalStates returns the terminal states in StateNames order.
+func (c Config) TerminalStates() []string {
+	var terminal []string
+	for _, s := range c.StateNames() {
+		if c.IsTerminal(s) {
+			terminal = append(terminal, s)
+		}
+	}
+	return terminal
 }
 
 func DefaultConfig() Config {
@@ -52,6 +330,7 @@ func DefaultConfig() Config {
 			"cancelled": {"open"},
 		},
 		DefaultState: "open",
+		States:       maps.Clone(builtinStates),
 		Types:        []string{"feature", "bug", "chore"},
 		DefaultType:  "feature",
 		IDLength:     6,
@@ -0,0 +1,233 @@
+package tracker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// ArchiveStore is implemented by stores that can move issues into
+// compressed archives instead of deleting them. Archives are named by
+// the month their issues were closed in, e.g. "2026-01".
+type ArchiveStore interface {
+	// ArchiveIssues moves the issues' documents and event streams into
+	// the named archive, replacing earlier copies of the same issues.
+	ArchiveIssues(archive string, ids []string) error
+	// ArchivedIssues maps the ID of every archived issue to its archive.
+	ArchivedIssues() (map[string]string, error)
+	// UnarchiveIssue moves an issue from the named archive back among
+	// the stored issues.
+	UnarchiveIssue(archive, id string) error
+}
+
+// archiveFile is one file of an archived issue directory, stored in the
+// tarball as "<id>/<name>".
+type archiveFile struct {
+	id      string
+	name    string
+	modTime time.Time
+	data    []byte
+}
+
+// readArchive returns the files in a tar.gz archive, or nothing if it
+// doesn't exist. Entries that aren't a plain file one level below an
+// issue directory are rejected rather than extracted.
+func readArchive(file string) ([]archiveFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	tr := tar.NewReader(gz)
+	var files []archiveFile
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		id, name, ok := strings.Cut(hdr.Name, "/")
+		if !ok || hdr.Typeflag != tar.TypeReg || id == "" || id == ".." ||
+			name == "" || name == ".." || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("reading %s: unexpected entry %q", file, hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		files = append(files, archiveFile{id: id, name: name, modTime: hdr.ModTime, data: data})
+	}
+}
+
+// writeArchive atomically replaces file, inside workDir, with a tar.gz of
+// files, sorted so that the same contents always produce the same archive.
+func writeArchive(workDir, file string, files []archiveFile) error {
+	slices.SortFunc(files, func(a, b archiveFile) int {
+		return strings.Compare(path.Join(a.id, a.name), path.Join(b.id, b.name))
+	})
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    path.Join(f.id, f.name),
+			Mode:    0o644,
+			Size:    int64(len(f.data)),
+			ModTime: f.modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(workDir, file, buf.Bytes(), 0o644)
+}
+
+// archiveName returns the archive an issue belongs in: the month, in
+// UTC, that it was last updated.
+func archiveName(issue model.Issue) string {
+	return issue.Updated.UTC().Format("2006-01")
+}
+
+func (t *Tracker) archiveStore() (ArchiveStore, error) {
+	store, ok := t.Store.(ArchiveStore)
+	if !ok {
+		return nil, fmt.Errorf("store does not support archives")
+	}
+	return store, nil
+}
+
+// ArchiveIssues removes issues from the tracker like PurgeIssue, but
+// first moves their full directories, history included, into a monthly
+// archive under .work/archive/. Each issue's log entry records the
+// archive, so it can be brought back with RestoreIssue.
+func (t *Tracker) ArchiveIssues(issues []model.Issue) error {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return t.archiveIssues(issues)
+}
+
+// archiveIssues is ArchiveIssues for callers holding the tracker-wide
+// lock.
+func (t *Tracker) archiveIssues(issues []model.Issue) error {
+	store, err := t.archiveStore()
+	if err != nil {
+		return err
+	}
+	byArchive := make(map[string][]string)
+	var entries []LogEntry
+	for _, issue := range issues {
+		archive := archiveName(issue)
+		byArchive[archive] = append(byArchive[archive], issue.ID)
+		entry := newLogEntry(issue)
+		entry.Archive = archive
+		entries = append(entries, entry)
+	}
+	if err := t.setLogEntries(entries); err != nil {
+		return fmt.Errorf("logging archived issues: %w", err)
+	}
+	for _, archive := range slices.Sorted(maps.Keys(byArchive)) {
+		if err := store.ArchiveIssues(archive, byArchive[archive]); err != nil {
+			return fmt.Errorf("archiving to %s: %w", archive, err)
+		}
+	}
+	return nil
+}
+
+// ArchivedIssues maps the ID of every archived issue to its archive.
+func (t *Tracker) ArchivedIssues() (map[string]string, error) {
+	store, err := t.archiveStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.ArchivedIssues()
+}
+
+// RestoreIssue brings an archived issue back into issues/ with its full
+// history. prefix may be any unique prefix of an archived ID. A
+// "restore" event is appended to the history and the issue's log entry
+// is refreshed to match it. Returns the restored issue.
+func (t *Tracker) RestoreIssue(prefix, user string) (model.Issue, error) {
+	store, err := t.archiveStore()
+	if err != nil {
+		return model.Issue{}, err
+	}
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return model.Issue{}, err
+	}
+	defer unlock()
+
+	archived, err := store.ArchivedIssues()
+	if err != nil {
+		return model.Issue{}, fmt.Errorf("reading archives: %w", err)
+	}
+	tree := NewPrefixTree(slices.Collect(maps.Keys(archived)))
+	matches := tree.Resolve(prefix)
+	switch len(matches) {
+	case 0:
+		return model.Issue{}, fmt.Errorf("no archived issue found with prefix %q", prefix)
+	case 1:
+	default:
+		return model.Issue{}, ambiguousPrefix(prefix, tree, matches, func(id string) string {
+			return "(" + archived[id] + ")"
+		})
+	}
+	id := matches[0]
+
+	if err := store.UnarchiveIssue(archived[id], id); err != nil {
+		return model.Issue{}, fmt.Errorf("restoring %s: %w", id, err)
+	}
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		return model.Issue{}, err
+	}
+	// The log keeps the original closing time.
+	entry := newLogEntry(issue)
+	now := time.Now().UTC()
+	issue.Updated = now
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, err
+	}
+	if err := t.AppendEvent(id, model.Event{Timestamp: now, Op: "restore", By: user}); err != nil {
+		return model.Issue{}, err
+	}
+	if err := t.setLogEntries([]LogEntry{entry}); err != nil {
+		return model.Issue{}, fmt.Errorf("updating log: %w", err)
+	}
+	return issue, nil
+}
@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestArchiveAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	var done []model.Issue
+	for _, title := range []string{"First", "Second"} {
+		issue, err := tr.CreateIssue(title, "details", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := tr.AddComment(issue.ID, "a note", "testuser"); err != nil {
+			t.Fatalf("comment: %v", err)
+		}
+		issue, err = tr.SetStatus(issue.ID, "done", "testuser")
+		if err != nil {
+			t.Fatalf("close: %v", err)
+		}
+		done = append(done, issue)
+	}
+	closed := done[0].Updated
+	// Closed in different months, so they land in different archives.
+	done[0].Updated = time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC)
+	done[1].Updated = time.Date(2026, 2, 1, 1, 0, 0, 0, time.UTC)
+
+	if err := tr.ArchiveIssues(done); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	for _, name := range []string{"2026-01.tar.gz", "2026-02.tar.gz"} {
+		if _, err := os.Stat(filepath.Join(dir, ".work", "archive", name)); err != nil {
+			t.Errorf("archive %s: %v", name, err)
+		}
+	}
+	if ids, _ := tr.Store.ListIssueIDs(); len(ids) != 0 {
+		t.Fatalf("issues left after archive: %v", ids)
+	}
+	archived, err := tr.ArchivedIssues()
+	if err != nil {
+		t.Fatalf("archived: %v", err)
+	}
+	if archived[done[0].ID] != "2026-01" || archived[done[1].ID] != "2026-02" {
+		t.Errorf("archived = %v", archived)
+	}
+	entries, err := tr.LoadLog()
+	if err != nil {
+		t.Fatalf("load log: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Archive == "" || entries[1].Archive == "" {
+		t.Fatalf("log entries = %+v, want both archived", entries)
+	}
+
+	restored, err := tr.RestoreIssue(done[0].ID[:4], "testuser")
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.ID != done[0].ID || restored.Description != "details" || len(restored.Comments) != 1 {
+		t.Errorf("restored = %+v", restored)
+	}
+	events, err := tr.LoadEvents(restored.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if last := events[len(events)-1]; last.Op != "restore" {
+		t.Errorf("last event = %q, want restore", last.Op)
+	}
+	replayed, err := tr.ReplayIssue(restored.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if mismatches, err := ReplayMismatches(restored, replayed); err != nil || len(mismatches) != 0 {
+		t.Errorf("replay after restore: %v, %v", mismatches, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".work", "archive", "2026-01.tar.gz")); !os.IsNotExist(err) {
+		t.Errorf("empty archive not removed: %v", err)
+	}
+
+	entries, err = tr.LoadLog()
+	if err != nil {
+		t.Fatalf("load log: %v", err)
+	}
+	for _, e := range entries {
+		if e.ID == restored.ID && (e.Archive != "" || !e.Closed.Equal(closed)) {
+			t.Errorf("log entry not reconciled: %+v", e)
+		}
+	}
+
+	// The restore marker is skipped; undo reaches the change before it.
+	undone, err := tr.UndoIssue(restored.ID, 1, "testuser")
+	if err != nil || undone[0].Op != "status" {
+		t.Errorf("undo after restore: %v, %v", undone, err)
+	}
+
+	if _, err := tr.RestoreIssue(done[0].ID, "testuser"); err == nil || !strings.Contains(err.Error(), "no archived issue") {
+		t.Errorf("second restore: got %v", err)
+	}
+}
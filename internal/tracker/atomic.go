@@ -0,0 +1,202 @@
+package tracker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempMarker separates the encoded target of an in-flight temp file from
+// its random suffix, e.g. "issues%2Fab12cd%2Fissue.json.tmp-123456".
+const tempMarker = ".tmp-"
+
+// tempDir returns the directory under workDir that holds in-flight temp
+// files. Keeping them in one place lets Recover find leftovers without
+// reading every issue directory.
+func tempDir(workDir string) string {
+	return filepath.Join(workDir, "tmp")
+}
+
+// writeFileAtomic replaces path, which must lie inside workDir, with data
+// so that readers observe either the old or the new contents, never a
+// partial write. The data is written to a temp file in workDir's temp
+// directory, fsynced, then renamed into place.
+func writeFileAtomic(workDir, path string, data []byte, perm os.FileMode) error {
+	rel, err := filepath.Rel(workDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%s is outside %s", path, workDir)
+	}
+	dir := tempDir(workDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// Temp files are runtime state; keep them out of git.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0o644)
+	}
+	f, err := os.CreateTemp(dir, url.QueryEscape(filepath.ToSlash(rel))+tempMarker+"*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	cleanup := func() {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+	}
+	if _, err := f.Write(data); err != nil {
+		cleanup()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		cleanup()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		cleanup()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir flushes a directory entry update to disk. Failures are ignored
+// because not every platform supports fsync on directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// tempTarget returns the file, relative to the work directory, that the
+// temp file named name was meant to replace. Names that don't decode to a
+// path inside the work directory are not temp files.
+func tempTarget(name string) (string, bool) {
+	idx := strings.LastIndex(name, tempMarker)
+	if idx <= 0 {
+		return "", false
+	}
+	rel, err := url.QueryUnescape(name[:idx])
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.FromSlash(rel)
+	if !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
+// tempFiles returns the names of the temp files in the temp directory.
+func (s *FSStore) tempFiles() ([]string, error) {
+	entries, err := os.ReadDir(tempDir(s.Dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var temps []string
+	for _, e := range entries {
+		if _, ok := tempTarget(e.Name()); ok && !e.IsDir() {
+			temps = append(temps, e.Name())
+		}
+	}
+	return temps, nil
+}
+
+// Recover cleans up temp files left behind by an interrupted atomic write.
+// A temp file is promoted only when its target is missing and its contents
+// are complete; otherwise it is discarded, since the target still holds
+// the last successful write. Returns a description of each action taken.
+//
+// Temp files belonging to a live writer are indistinguishable from
+// leftovers, so recovery only runs when the tracker-wide lock is free;
+// otherwise it is skipped until the next quiet load. The lock isn't taken
+// at all when there are no temp files.
+func (s *FSStore) Recover() ([]string, error) {
+	if _, err := os.Stat(s.Dir); err != nil {
+		return nil, nil
+	}
+	temps, err := s.tempFiles()
+	if err != nil || len(temps) == 0 {
+		return nil, err
+	}
+	unlock, ok, err := s.TryLock(trackerLockName, true)
+	if err != nil || !ok {
+		return nil, err
+	}
+	defer unlock()
+
+	var actions []string
+	for _, name := range temps {
+		path := filepath.Join(tempDir(s.Dir), name)
+		rel, _ := tempTarget(name)
+		target := filepath.Join(s.Dir, rel)
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			data, readErr := os.ReadFile(path)
+			if os.IsNotExist(readErr) {
+				// Its writer finished before we took the lock.
+				continue
+			}
+			// A target whose directory is gone was removed since, e.g. by
+			// a purge; there is nothing to restore.
+			_, dirErr := os.Stat(filepath.Dir(target))
+			if readErr == nil && dirErr == nil && completeContents(target, data) {
+				if err := os.Rename(path, target); err != nil {
+					return actions, fmt.Errorf("restoring %s: %w", rel, err)
+				}
+				actions = append(actions, fmt.Sprintf("restored %s from interrupted write", rel))
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return actions, fmt.Errorf("removing temp file for %s: %w", rel, err)
+		}
+		actions = append(actions, fmt.Sprintf("discarded interrupted write to %s", rel))
+	}
+	return actions, nil
+}
+
+// completeContents reports whether data looks like a fully written
+// replacement for target: valid JSON for .json files, and valid JSON on
+// every line (with a trailing newline) for .jsonl files.
+func completeContents(target string, data []byte) bool {
+	switch filepath.Ext(target) {
+	case ".json":
+		return json.Valid(data)
+	case ".jsonl":
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			return false
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, len(data)+1)
+		for scanner.Scan() {
+			if !json.Valid(scanner.Bytes()) {
+				return false
+			}
+		}
+		return scanner.Err() == nil
+	default:
+		return false
+	}
+}
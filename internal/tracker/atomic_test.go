@@ -0,0 +1,199 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func listTempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var temps []string
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.Contains(info.Name(), tempMarker) {
+			temps = append(temps, path)
+		}
+		return nil
+	})
+	return temps
+}
+
+func TestWriteFileAtomic_ReplacesContents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "issue.json")
+	if err := os.WriteFile(path, []byte(`{"old":true}`), 0o644); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := writeFileAtomic(dir, path, []byte(`{"new":true}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != `{"new":true}` {
+		t.Errorf("contents: got %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("perm: got %v, want 0644", info.Mode().Perm())
+	}
+	if temps := listTempFiles(t, dir); len(temps) != 0 {
+		t.Errorf("temp files left behind: %v", temps)
+	}
+	if err := writeFileAtomic(dir, filepath.Join(dir, "..", "escaped.json"), nil, 0o644); err == nil {
+		t.Error("write outside the work directory: want error")
+	}
+}
+
+func TestRewrites_LeaveNoTempFiles(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Atomic", "desc", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := tr.CompactIssue(issue.ID); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if _, err := tr.DeduplicateLog(); err != nil {
+		t.Fatalf("dedup: %v", err)
+	}
+	if temps := listTempFiles(t, filepath.Join(root, ".work")); len(temps) != 0 {
+		t.Errorf("temp files left behind: %v", temps)
+	}
+}
+
+func TestLoad_RecoversInterruptedWrites(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	now := time.Now().UTC()
+	kept := model.Issue{ID: "kept01", Title: "Kept", Status: "open", Created: now, Updated: now}
+	if err := tr.SaveIssue(kept); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	tmp := filepath.Join(root, ".work", "tmp")
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	// Target exists: the temp file is a stale partial write and is discarded.
+	staleTemp := filepath.Join(tmp, "issues%2Fkept01%2Fissue.json.tmp-111")
+	if err := os.WriteFile(staleTemp, []byte(`{"id":"kept01","tit`), 0o644); err != nil {
+		t.Fatalf("write stale temp: %v", err)
+	}
+
+	// Target missing with complete contents: promoted into place.
+	if err := os.MkdirAll(filepath.Join(root, ".work", "issues", "new001"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	goodTemp := filepath.Join(tmp, "issues%2Fnew001%2Fissue.json.tmp-222")
+	if err := os.WriteFile(goodTemp, []byte(`{"id":"new001","title":"New","status":"open"}`+"\n"), 0o644); err != nil {
+		t.Fatalf("write good temp: %v", err)
+	}
+
+	// Target missing with truncated JSONL: discarded.
+	badTemp := filepath.Join(tmp, "log.jsonl.tmp-333")
+	if err := os.WriteFile(badTemp, []byte(`{"id":"a"}`+"\n"+`{"id":`), 0o644); err != nil {
+		t.Fatalf("write bad temp: %v", err)
+	}
+
+	// Target's directory is gone: discarded.
+	goneTemp := filepath.Join(tmp, "issues%2Fgone01%2Fissue.json.tmp-444")
+	if err := os.WriteFile(goneTemp, []byte(`{"id":"gone01"}`), 0o644); err != nil {
+		t.Fatalf("write gone temp: %v", err)
+	}
+
+	loaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Recovered) != 4 {
+		t.Errorf("recovered: got %d actions, want 4: %v", len(loaded.Recovered), loaded.Recovered)
+	}
+	if temps := listTempFiles(t, filepath.Join(root, ".work")); len(temps) != 0 {
+		t.Errorf("temp files left behind: %v", temps)
+	}
+	if got, err := loaded.LoadIssue("kept01"); err != nil || got.Title != "Kept" {
+		t.Errorf("kept issue: got %+v, %v", got, err)
+	}
+	if got, err := loaded.LoadIssue("new001"); err != nil || got.Title != "New" {
+		t.Errorf("promoted issue: got %+v, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".work", "log.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("truncated log temp should not be promoted, stat err = %v", err)
+	}
+	if _, err := loaded.ListIssues(); err != nil {
+		t.Errorf("list after recovery: %v", err)
+	}
+}
+
+func TestLoad_RecoverOnlyLooksWhereWritesGo(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Init(root); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	hooks := filepath.Join(root, ".work", "hooks")
+	if err := os.MkdirAll(hooks, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	other := filepath.Join(hooks, ".pre-create.tmp-1")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// Left next to its target by an older version; only the temp
+	// directory is read.
+	legacy := filepath.Join(root, ".work", ".log.jsonl.tmp-2")
+	if err := os.WriteFile(legacy, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	loaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Recovered) != 0 {
+		t.Errorf("recovered: %v", loaded.Recovered)
+	}
+	for _, path := range []string{other, legacy} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("file outside the temp directory was touched: %v", err)
+		}
+	}
+}
+
+func TestTempTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		ok     bool
+	}{
+		{"issues%2Fab12%2Fissue.json.tmp-123", filepath.Join("issues", "ab12", "issue.json"), true},
+		{"log.jsonl.tmp-9", "log.jsonl", true},
+		{"issue.json", "", false},
+		{".tmp-123", "", false},
+		{"..%2Fconfig.json.tmp-1", "", false},
+		{"%2Fetc%2Fpasswd.tmp-1", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tempTarget(tt.name)
+		if ok != tt.ok || got != tt.target {
+			t.Errorf("tempTarget(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.target, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// AutomationUser is who changes made by automation rules are recorded as.
+const AutomationUser = "automation"
+
+// maxAutomationDepth bounds how long a chain of rules triggering each
+// other may get, so that rules moving an issue back and forth stop.
+const maxAutomationDepth = 8
+
+// automate applies the automation rules triggered by event, the change
+// actor just made to issue. Rules that fail are reported on HookOutput
+// and do not undo the change. It returns the IDs of the issues the rules
+// changed.
+func (t *Tracker) automate(issue model.Issue, event model.Event, actor string, depth int) []string {
+	if t.NoAutomation || depth >= maxAutomationDepth {
+		return nil
+	}
+	var changed []string
+	for _, rule := range t.Config.Automation {
+		targetID, err := t.triggered(rule, issue, event)
+		if err == nil && targetID != "" {
+			var applied []string
+			applied, err = t.applyRule(rule, targetID, actor, depth)
+			changed = append(changed, applied...)
+		}
+		if err != nil && t.HookOutput != nil {
+			fmt.Fprintf(t.HookOutput, "warning: automation rule %s: %v\n", ruleName(rule), err)
+		}
+	}
+	return changed
+}
+
+// automateChange applies the automation rules triggered by events, the
+// changes user just made to issue, and returns issue as it is after
+// them.
+func (t *Tracker) automateChange(issue model.Issue, events []model.Event, user string) (model.Issue, error) {
+	changed := false
+	for _, event := range events {
+		if slices.Contains(t.automate(issue, event, user, 0), issue.ID) {
+			changed = true
+		}
+	}
+	if changed {
+		return t.LoadIssue(issue.ID)
+	}
+	return issue, nil
+}
+
+func ruleName(r model.Rule) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("%q", r.When)
+}
+
+// triggered returns the ID of the issue rule acts on if event fires it,
+// or "" if it does not.
+func (t *Tracker) triggered(rule model.Rule, issue model.Issue, event model.Event) (string, error) {
+	switch rule.When {
+	case model.TriggerEnters:
+		entered := (event.Op == "status" && event.To == rule.State) ||
+			(event.Op == "create" && issue.Status == rule.State)
+		if entered {
+			return issue.ID, nil
+		}
+	case model.TriggerChildrenTerminal:
+		// The last open child either reached a terminal state or was
+		// moved out from under its parent.
+		var parentID string
+		switch {
+		case event.Op == "status" && t.Config.IsTerminal(event.To):
+			parentID = issue.ParentID
+		case (event.Op == "unlink" || event.Op == "link") && event.From != "" && !t.Config.IsTerminal(issue.Status):
+			parentID = event.From
+		}
+		if parentID == "" {
+			return "", nil
+		}
+		issues, err := t.ListSummaries()
+		if err != nil {
+			return "", err
+		}
+		children := 0
+		for _, child := range issues {
+			if child.ParentID != parentID {
+				continue
+			}
+			if !t.Config.IsTerminal(child.Status) {
+				return "", nil
+			}
+			children++
+		}
+		if children == 0 {
+			return "", nil
+		}
+		return parentID, nil
+	case model.TriggerReopened:
+		if event.Op != "status" || !t.Config.IsTerminal(event.From) || t.Config.IsTerminal(event.To) {
+			return "", nil
+		}
+		events, err := t.LoadEvents(issue.ID)
+		if err != nil {
+			return "", err
+		}
+		reopens := 0
+		for _, ev := range events {
+			if ev.Op == "status" && t.Config.IsTerminal(ev.From) && !t.Config.IsTerminal(ev.To) {
+				reopens++
+			}
+		}
+		if reopens >= max(rule.Count, 1) {
+			return issue.ID, nil
+		}
+	default:
+		return "", fmt.Errorf("unknown trigger %q", rule.When)
+	}
+	return "", nil
+}
+
+// applyRule applies rule's actions to the issue id, unless it fails the
+// rule's filters, and returns the IDs of the issues it changed. A status
+// change runs the transition hooks and the rules it triggers in turn,
+// whose changes are included.
+func (t *Tracker) applyRule(rule model.Rule, id, actor string, depth int) ([]string, error) {
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	if (rule.Type != "" && issue.Type != rule.Type) || (rule.Unassigned && issue.Assignee != "") {
+		return nil, nil
+	}
+
+	var changed []string
+	edited := issue
+	if rule.AssignActor && issue.Assignee == "" && actor != "" {
+		edited.Assignee = actor
+	}
+	if rule.AddLabel != "" && !slices.Contains(issue.Labels, rule.AddLabel) {
+		edited.Labels = append(slices.Clone(issue.Labels), rule.AddLabel)
+	}
+	if len(ChangedFields(issue, edited)) > 0 {
+		saved, conflicts, err := t.saveRuleEdit(issue, edited, rule.SetStatus)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%s changed while applying the rule", id)
+		}
+		issue = saved
+		changed = append(changed, id)
+	}
+
+	if rule.SetStatus != "" && issue.Status != rule.SetStatus {
+		moved, event, err := t.setStatus(id, rule.SetStatus, AutomationUser)
+		if err != nil {
+			return changed, err
+		}
+		changed = append(changed, id)
+		t.postTransition(moved, []model.Event{event}, AutomationUser)
+		changed = append(changed, t.automate(moved, event, actor, depth+1)...)
+	}
+	return changed, nil
+}
+
+// saveRuleEdit saves the fields a rule changed. A new assignee counts
+// toward the WIP limit of the state the issue is in or, with setStatus,
+// about to enter, so it is checked against that limit first.
+func (t *Tracker) saveRuleEdit(issue, edited model.Issue, setStatus string) (model.Issue, []FieldConflict, error) {
+	state := issue.Status
+	if setStatus != "" {
+		state = setStatus
+	}
+	held := false
+	if edited.Assignee != issue.Assignee {
+		unlock, locked, err := t.lockState(state)
+		if err != nil {
+			return model.Issue{}, nil, err
+		}
+		defer unlock()
+		held = locked
+		if err := t.checkWIPLimit(edited, state); err != nil {
+			return model.Issue{}, nil, err
+		}
+	}
+	saved, conflicts, _, err := t.saveEdit(issue, edited, AutomationUser, held)
+	return saved, conflicts, err
+}
@@ -0,0 +1,170 @@
+package tracker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestAutomation(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.Transitions["open"] = append(cfg.Transitions["open"], "review")
+	cfg.Automation = []model.Rule{
+		{Name: "parent to review", When: model.TriggerChildrenTerminal, SetStatus: "review"},
+		{When: model.TriggerEnters, State: "active", Unassigned: true, AssignActor: true},
+		{When: model.TriggerReopened, Count: 2, Type: "bug", AddLabel: "flaky"},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	parent, err := tr.CreateIssue("Parent", "", "", 2, nil, "", "", "alice")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var children []model.Issue
+	for _, title := range []string{"One", "Two"} {
+		child, err := tr.CreateIssue(title, "", "", 2, nil, "", parent.ID, "alice")
+		if err != nil {
+			t.Fatalf("create child: %v", err)
+		}
+		children = append(children, child)
+	}
+
+	started, err := tr.SetStatus(children[0].ID, "active", "bob")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if started.Assignee != "bob" {
+		t.Errorf("assignee after start = %q, want the acting user", started.Assignee)
+	}
+	events, _ := tr.LoadEvents(children[0].ID)
+	if last := events[len(events)-1]; last.Op != "edit" || last.By != AutomationUser {
+		t.Errorf("last event = %+v, want an edit by %s", last, AutomationUser)
+	}
+
+	if _, err := tr.SetStatus(children[0].ID, "done", "bob"); err != nil {
+		t.Fatalf("close one: %v", err)
+	}
+	if p, _ := tr.LoadIssue(parent.ID); p.Status != "open" {
+		t.Errorf("parent moved to %q with a child still open", p.Status)
+	}
+	if _, err := tr.SetStatus(children[1].ID, "cancelled", "bob"); err != nil {
+		t.Fatalf("cancel two: %v", err)
+	}
+	p, _ := tr.LoadIssue(parent.ID)
+	if p.Status != "review" {
+		t.Errorf("parent status = %q, want review", p.Status)
+	}
+	events, _ = tr.LoadEvents(parent.ID)
+	if last := events[len(events)-1]; last.Op != "status" || last.To != "review" || last.By != AutomationUser {
+		t.Errorf("parent's last event = %+v", last)
+	}
+
+	bug, err := tr.CreateIssue("Flaky", "", "", 2, nil, "bug", "", "alice")
+	if err != nil {
+		t.Fatalf("create bug: %v", err)
+	}
+	for i := range 2 {
+		if _, err := tr.SetStatus(bug.ID, "done", "alice"); err != nil {
+			t.Fatalf("close bug: %v", err)
+		}
+		reopened, err := tr.SetStatus(bug.ID, "open", "alice")
+		if err != nil {
+			t.Fatalf("reopen bug: %v", err)
+		}
+		if flaky := slices.Contains(reopened.Labels, "flaky"); flaky != (i == 1) {
+			t.Errorf("after reopen %d labels = %v", i+1, reopened.Labels)
+		}
+	}
+
+	tr.NoAutomation = true
+	chore, err := tr.CreateIssue("Quiet", "", "", 2, nil, "chore", "", "alice")
+	if err != nil {
+		t.Fatalf("create chore: %v", err)
+	}
+	if started, err := tr.SetStatus(chore.ID, "active", "alice"); err != nil || started.Assignee != "" {
+		t.Errorf("start with automation off = %q, %v", started.Assignee, err)
+	}
+}
+
+func TestAutomation_AssignRespectsWIPLimit(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.WIPLimits = map[string]model.WIPLimit{"active": {PerAssignee: 1}}
+	cfg.Automation = []model.Rule{{When: model.TriggerEnters, State: "active", Unassigned: true, AssignActor: true}}
+	tr := New(cfg, NewMemoryStore())
+	var out strings.Builder
+	tr.HookOutput = &out
+
+	var started []model.Issue
+	for _, title := range []string{"One", "Two"} {
+		issue, err := tr.CreateIssue(title, "", "", 2, nil, "", "", "alice")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		issue, err = tr.SetStatus(issue.ID, "active", "alice")
+		if err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		started = append(started, issue)
+	}
+	if started[0].Assignee != "alice" {
+		t.Errorf("first assignee = %q, want alice", started[0].Assignee)
+	}
+	// Assigning the second would put alice over her limit.
+	if started[1].Assignee != "" {
+		t.Errorf("second assignee = %q, want none", started[1].Assignee)
+	}
+	if !strings.Contains(out.String(), "alice already has 1/1") {
+		t.Errorf("output = %q, want the WIP limit reported", out.String())
+	}
+}
+
+func TestAutomation_OtherMutations(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.Transitions["open"] = append(cfg.Transitions["open"], "review")
+	cfg.Automation = []model.Rule{
+		{When: model.TriggerChildrenTerminal, SetStatus: "review"},
+		{When: model.TriggerEnters, State: "active", Unassigned: true, AssignActor: true},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	parent, err := tr.CreateIssue("Parent", "", "", 2, nil, "", "", "alice")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	done, err := tr.CreateIssue("Done", "", "", 2, nil, "", parent.ID, "alice")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	open, err := tr.CreateIssue("Open", "", "", 2, nil, "", parent.ID, "alice")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := tr.SetStatus(done.ID, "done", "alice"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// Unlinking the last open child leaves only terminal children.
+	if _, err := tr.UnlinkIssue(open.ID, "alice"); err != nil {
+		t.Fatalf("unlink: %v", err)
+	}
+	if p, _ := tr.LoadIssue(parent.ID); p.Status != "review" {
+		t.Errorf("parent status after unlink = %q, want review", p.Status)
+	}
+
+	// Undoing a move out of active enters active again.
+	tr.NoAutomation = true
+	if _, err := tr.SetStatus(open.ID, "active", "alice"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := tr.SetStatus(open.ID, "open", "alice"); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	tr.NoAutomation = false
+	if _, err := tr.UndoIssue(open.ID, 1, "bob"); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if got, _ := tr.LoadIssue(open.ID); got.Status != "active" || got.Assignee != "bob" {
+		t.Errorf("after undo: status %q assignee %q, want active and bob", got.Status, got.Assignee)
+	}
+}
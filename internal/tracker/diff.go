@@ -0,0 +1,160 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// DiffLine is one line of a line-based diff. Op is ' ' for a line common
+// to both sides, '-' for a removed line and '+' for an added one.
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// DiffLines computes a minimal line diff from before to after using the
+// longest common subsequence of their lines. It needs memory linear in
+// the number of lines, so long descriptions are cheap to diff.
+func DiffLines(before, after string) []DiffLine {
+	a := splitText(before)
+	b := splitText(after)
+
+	// Lines shared at either end are common to every LCS.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{' ', line})
+	}
+	diff = diffMiddle(diff, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{' ', line})
+	}
+	return diff
+}
+
+// diffMiddle appends the diff from a to b to diff using Hirschberg's
+// algorithm: a is split in half and b where the LCS lengths of the two
+// halves sum to the most, and each half is diffed in turn, so that only
+// one row of LCS lengths per side is held at a time.
+func diffMiddle(diff []DiffLine, a, b []string) []DiffLine {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			diff = append(diff, DiffLine{'+', line})
+		}
+		return diff
+	case len(b) == 0:
+		for _, line := range a {
+			diff = append(diff, DiffLine{'-', line})
+		}
+		return diff
+	case len(a) == 1:
+		j := slices.Index(b, a[0])
+		if j < 0 {
+			diff = append(diff, DiffLine{'-', a[0]})
+			return diffMiddle(diff, nil, b)
+		}
+		diff = diffMiddle(diff, nil, b[:j])
+		diff = append(diff, DiffLine{' ', a[0]})
+		return diffMiddle(diff, nil, b[j+1:])
+	}
+
+	mid := len(a) / 2
+	head := lcsLengths(a[:mid], b)
+	tail := lcsLengths(reversed(a[mid:]), reversed(b))
+	split, best := 0, -1
+	for j := range len(b) + 1 {
+		// head[j] covers b[:j] and tail[len(b)-j] covers b[j:].
+		if n := head[j] + tail[len(b)-j]; n > best {
+			split, best = j, n
+		}
+	}
+	diff = diffMiddle(diff, a[:mid], b[:split])
+	return diffMiddle(diff, a[mid:], b[split:])
+}
+
+// lcsLengths returns, for each j, the length of the longest common
+// subsequence of a and b[:j].
+func lcsLengths(a, b []string) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reversed(lines []string) []string {
+	r := slices.Clone(lines)
+	slices.Reverse(r)
+	return r
+}
+
+func splitText(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// FormatValue renders a field value recorded in an event for display:
+// strings without quotes, lists comma-separated, anything else as JSON.
+func FormatValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, ", ")
+	}
+	return string(raw)
+}
+
+// RenderChanges renders the before/after values of an edit as indented
+// lines: "field: old → new" for single-line values, and the field followed
+// by a line diff for multi-line ones such as the description. style
+// decorates each piece for display; op is ':' for a field name, '-' and
+// '+' for old and new values or lines, and ' ' for unchanged lines.
+func RenderChanges(changes []model.FieldChange, style func(op byte, s string) string) []string {
+	var lines []string
+	for _, c := range changes {
+		from, to := FormatValue(c.From), FormatValue(c.To)
+		field := style(':', c.Field+":")
+		if !strings.Contains(from, "\n") && !strings.Contains(to, "\n") {
+			lines = append(lines, fmt.Sprintf("    %s %s → %s", field, style('-', quoteEmpty(from)), style('+', quoteEmpty(to))))
+			continue
+		}
+		lines = append(lines, "    "+field)
+		for _, d := range DiffLines(from, to) {
+			lines = append(lines, "      "+style(d.Op, fmt.Sprintf("%c %s", d.Op, d.Text)))
+		}
+	}
+	return lines
+}
+
+func quoteEmpty(s string) string {
+	if s == "" {
+		return `""`
+	}
+	return s
+}
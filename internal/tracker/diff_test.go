@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := DiffLines("a\nb\nc", "a\nc\nd")
+	want := []DiffLine{{' ', "a"}, {'-', "b"}, {' ', "c"}, {'+', "d"}}
+	if len(got) != len(want) {
+		t.Fatalf("diff: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if d := DiffLines("", "x"); len(d) != 1 || d[0].Op != '+' {
+		t.Errorf("from empty: got %+v", d)
+	}
+}
+
+func TestDiffLines_Minimal(t *testing.T) {
+	// lcs is the textbook quadratic LCS length, to check against.
+	lcs := func(a, b []string) int {
+		table := make([][]int, len(a)+1)
+		for i := range table {
+			table[i] = make([]int, len(b)+1)
+		}
+		for i := range a {
+			for j := range b {
+				if a[i] == b[j] {
+					table[i+1][j+1] = table[i][j] + 1
+				} else {
+					table[i+1][j+1] = max(table[i][j+1], table[i+1][j])
+				}
+			}
+		}
+		return table[len(a)][len(b)]
+	}
+	r := rand.New(rand.NewPCG(1, 2))
+	text := func() string {
+		lines := make([]string, r.IntN(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + r.IntN(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+	for range 500 {
+		before, after := text(), text()
+		var from, to []string
+		common := 0
+		for _, d := range DiffLines(before, after) {
+			if d.Op != '+' {
+				from = append(from, d.Text)
+			}
+			if d.Op != '-' {
+				to = append(to, d.Text)
+			}
+			if d.Op == ' ' {
+				common++
+			}
+		}
+		if strings.Join(from, "\n") != before || strings.Join(to, "\n") != after {
+			t.Fatalf("diff of %q and %q doesn't rebuild them", before, after)
+		}
+		if want := lcs(splitText(before), splitText(after)); common != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d", before, after, common, want)
+		}
+	}
+}
+
+func TestRenderChanges(t *testing.T) {
+	changes := []model.FieldChange{
+		{Field: "title", From: json.RawMessage(`"Old"`), To: json.RawMessage(`""`)},
+		{Field: "description", From: json.RawMessage(`"a\nb"`), To: json.RawMessage(`"a\nc"`)},
+	}
+	got := RenderChanges(changes, func(op byte, s string) string { return "[" + string(op) + s + "]" })
+	want := []string{
+		`    [:title:] [-Old] → [+""]`,
+		`    [:description:]`,
+		`      [   a]`,
+		`      [-- b]`,
+		`      [++ c]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[string]string{
+		`"hello"`:   "hello",
+		`["a","b"]`: "a, b",
+		`3`:         "3",
+		`null`:      "",
+		`{"k":"v"}`: `{"k":"v"}`,
+	}
+	for raw, want := range tests {
+		if got := FormatValue(json.RawMessage(raw)); got != want {
+			t.Errorf("FormatValue(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
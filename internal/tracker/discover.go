@@ -0,0 +1,38 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoTracker is returned by FindRoot when no .work/ directory is found.
+var ErrNoTracker = errors.New("no .work/ directory found")
+
+// FindRoot locates the tracker that governs dir, the way git locates a
+// repository: it checks dir and then each parent for a .work/ directory.
+// The search stops at the filesystem root, or after checking the first
+// directory that contains .git, so that a project never picks up a
+// tracker belonging to an enclosing repository.
+func FindRoot(dir string) (string, error) {
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	notFound := fmt.Errorf("%w in %s or its parents (run 'work init' to create one)", ErrNoTracker, start)
+	dir = start
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".work")); err == nil && info.IsDir() {
+			return dir, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", notFound
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", notFound
+		}
+		dir = parent
+	}
+}
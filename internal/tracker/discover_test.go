@@ -0,0 +1,57 @@
+package tracker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindRoot_WalksUp(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Init(root); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	sub := filepath.Join(root, "cmd", "deep")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, dir := range []string{root, sub} {
+		got, err := FindRoot(dir)
+		if err != nil {
+			t.Fatalf("find from %s: %v", dir, err)
+		}
+		if got != root {
+			t.Errorf("find from %s: got %s, want %s", dir, got, root)
+		}
+	}
+}
+
+func TestFindRoot_StopsAtGitBoundary(t *testing.T) {
+	outer := t.TempDir()
+	if _, err := Init(outer); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	repo := filepath.Join(outer, "vendor", "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	sub := filepath.Join(repo, "pkg")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if _, err := FindRoot(sub); !errors.Is(err, ErrNoTracker) {
+		t.Errorf("find inside nested repo: got %v, want ErrNoTracker", err)
+	}
+
+	if _, err := Init(repo); err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	got, err := FindRoot(sub)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got != repo {
+		t.Errorf("find: got %s, want %s", got, repo)
+	}
+}
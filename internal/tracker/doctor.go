@@ -0,0 +1,348 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// Problem codes reported by Doctor.
+const (
+	ProblemUnparseableIssue = "unparseable-issue"
+	ProblemIDMismatch       = "id-mismatch"
+	ProblemOrphanParent     = "orphan-parent"
+	ProblemUnknownStatus    = "unknown-status"
+	ProblemUnknownType      = "unknown-type"
+	ProblemMissingHistory   = "missing-history"
+	ProblemBadEventLine     = "bad-event-line"
+	ProblemBadLogLine       = "bad-log-line"
+	ProblemDuplicateLog     = "duplicate-log-entry"
+	ProblemBadCategory      = "bad-state-category"
+	ProblemBadWorkflow      = "bad-workflow"
+	ProblemBadGuard         = "bad-guard"
+	ProblemBadRule          = "bad-automation-rule"
+)
+
+// Problem is one integrity issue found by Doctor.
+type Problem struct {
+	Code    string `json:"code"`
+	IssueID string `json:"issue_id,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	Fixable bool   `json:"fixable"`
+	Fixed   bool   `json:"fixed,omitempty"`
+}
+
+// Doctor checks the tracker for problems that would make reads fail or
+// leave the tree inconsistent. With fix set, problems marked Fixable are
+// repaired under the tracker-wide lock; user is recorded on any events
+// the repairs append.
+func (t *Tracker) Doctor(fix bool, user string) ([]Problem, error) {
+	if fix {
+		unlock, err := t.LockTracker()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	logLines, err := t.readLogLines()
+	if err != nil {
+		return nil, err
+	}
+	purged := make(map[string]bool)
+	for _, l := range logLines {
+		var entry LogEntry
+		if json.Unmarshal(l.data, &entry) == nil {
+			purged[entry.ID] = true
+		}
+	}
+
+	problems := append(t.checkStates(), t.checkWorkflows()...)
+	problems = append(problems, t.checkGuards()...)
+	problems = append(problems, t.checkRules()...)
+	for _, id := range ids {
+		found, err := t.checkIssue(id, ids, purged, fix, user)
+		if err != nil {
+			return problems, err
+		}
+		problems = append(problems, found...)
+	}
+	found, err := t.checkLog(logLines, fix)
+	if err != nil {
+		return problems, err
+	}
+	return append(problems, found...), nil
+}
+
+// checkStates reports states in config.json declared with a category
+// other than the known ones.
+func (t *Tracker) checkStates() []Problem {
+	var problems []Problem
+	for _, name := range slices.Sorted(maps.Keys(t.Config.States)) {
+		category := t.Config.States[name].Category
+		if !slices.Contains(model.Categories, category) {
+			problems = append(problems, Problem{
+				Code: ProblemBadCategory,
+				Message: fmt.Sprintf("config.json: state %q has unknown category %q (allowed: %s)",
+					name, category, strings.Join(model.Categories, ", ")),
+			})
+		}
+	}
+	return problems
+}
+
+// checkWorkflows reports per-type workflows in config.json that name an
+// unknown type or start issues in a state they cannot leave.
+func (t *Tracker) checkWorkflows() []Problem {
+	var problems []Problem
+	for _, typ := range slices.Sorted(maps.Keys(t.Config.Workflows)) {
+		if ValidateType(t.Config, typ) != nil {
+			problems = append(problems, Problem{
+				Code:    ProblemBadWorkflow,
+				Message: fmt.Sprintf("config.json: workflow for unknown type %q", typ),
+			})
+		}
+		w := t.Config.Workflow(typ)
+		if !w.HasState(w.DefaultState) {
+			problems = append(problems, Problem{
+				Code:    ProblemBadWorkflow,
+				Message: fmt.Sprintf("config.json: workflow for %q has no transitions from its default state %q", typ, w.DefaultState),
+			})
+		}
+	}
+	return problems
+}
+
+// checkGuards reports guards in config.json with an unknown requirement.
+// Such guards block every transition into their state.
+func (t *Tracker) checkGuards() []Problem {
+	var problems []Problem
+	for _, state := range slices.Sorted(maps.Keys(t.Config.Guards)) {
+		for _, g := range t.Config.Guards[state] {
+			if !slices.Contains(model.Requirements, g.Require) {
+				problems = append(problems, Problem{
+					Code: ProblemBadGuard,
+					Message: fmt.Sprintf("config.json: guard on %q has unknown requirement %q (allowed: %s)",
+						state, g.Require, strings.Join(model.Requirements, ", ")),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// checkRules reports automation rules in config.json that can never do
+// anything: an unknown trigger, "enters" without a state, or no action.
+func (t *Tracker) checkRules() []Problem {
+	var problems []Problem
+	for i, rule := range t.Config.Automation {
+		var msg string
+		switch {
+		case !slices.Contains(model.Triggers, rule.When):
+			msg = fmt.Sprintf("unknown trigger %q (allowed: %s)", rule.When, strings.Join(model.Triggers, ", "))
+		case rule.When == model.TriggerEnters && rule.State == "":
+			msg = "\"enters\" needs a state"
+		case rule.SetStatus == "" && !rule.AssignActor && rule.AddLabel == "":
+			msg = "no action"
+		default:
+			continue
+		}
+		problems = append(problems, Problem{
+			Code:    ProblemBadRule,
+			Message: fmt.Sprintf("config.json: automation rule %d %s: %s", i+1, ruleName(rule), msg),
+		})
+	}
+	return problems
+}
+
+func (t *Tracker) checkIssue(id string, ids []string, purged map[string]bool, fix bool, user string) ([]Problem, error) {
+	var problems []Problem
+	// report records a problem and returns its index, so that repairs
+	// made after later appends can still mark it fixed.
+	report := func(p Problem) int {
+		p.IssueID = id
+		problems = append(problems, p)
+		return len(problems) - 1
+	}
+
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		report(Problem{Code: ProblemUnparseableIssue, Message: err.Error()})
+		return problems, nil
+	}
+
+	dirty := false
+	if issue.ID != id {
+		i := report(Problem{
+			Code:    ProblemIDMismatch,
+			Message: fmt.Sprintf("issue.json has id %q", issue.ID),
+			Fixable: true,
+		})
+		if fix {
+			issue.ID = id
+			dirty, problems[i].Fixed = true, true
+		}
+	}
+
+	var unlinked string
+	if issue.ParentID != "" && !slices.Contains(ids, issue.ParentID) {
+		msg := fmt.Sprintf("parent %s does not exist", issue.ParentID)
+		if purged[issue.ParentID] {
+			msg = fmt.Sprintf("parent %s was purged", issue.ParentID)
+		}
+		i := report(Problem{Code: ProblemOrphanParent, Message: msg, Fixable: true})
+		if fix {
+			unlinked = issue.ParentID
+			issue.ParentID = ""
+			dirty, problems[i].Fixed = true, true
+		}
+	}
+
+	if !t.Config.Workflow(issue.Type).HasState(issue.Status) {
+		report(Problem{
+			Code:    ProblemUnknownStatus,
+			Message: fmt.Sprintf("status %q is not in the workflow for type %q", issue.Status, issue.Type),
+		})
+	}
+	if ValidateType(t.Config, issue.Type) != nil {
+		report(Problem{
+			Code:    ProblemUnknownType,
+			Message: fmt.Sprintf("type %q is not in config types", issue.Type),
+		})
+	}
+
+	if dirty {
+		if err := t.saveIssue(&issue); err != nil {
+			return problems, fmt.Errorf("repairing %s: %w", id, err)
+		}
+	}
+
+	f, err := t.Store.OpenEvents(id)
+	if err != nil {
+		return problems, fmt.Errorf("opening history: %w", err)
+	}
+	lines, err := readRawLines(f)
+	_ = f.Close()
+	if err != nil {
+		return problems, fmt.Errorf("reading history: %w", err)
+	}
+	var good bytes.Buffer
+	var bad []int
+	for _, l := range lines {
+		var ev model.Event
+		if err := json.Unmarshal(l.data, &ev); err != nil {
+			bad = append(bad, report(Problem{
+				Code:    ProblemBadEventLine,
+				Line:    l.n,
+				Message: fmt.Sprintf("history.jsonl: %v", err),
+				Fixable: true,
+			}))
+			continue
+		}
+		good.Write(l.data)
+		good.WriteByte('\n')
+	}
+	missing := -1
+	if good.Len() == 0 {
+		missing = report(Problem{
+			Code:    ProblemMissingHistory,
+			Message: "history.jsonl has no events",
+			Fixable: true,
+		})
+	}
+
+	if !fix {
+		return problems, nil
+	}
+	if missing >= 0 {
+		initial := issue
+		initial.Revision = 0
+		data, err := json.Marshal(model.Event{Timestamp: issue.Created, Op: "create", By: user, Issue: &initial})
+		if err != nil {
+			return problems, err
+		}
+		good.Write(data)
+		good.WriteByte('\n')
+		problems[missing].Fixed = true
+	}
+	if missing >= 0 || len(bad) > 0 {
+		if err := t.Store.WriteEvents(id, good.Bytes()); err != nil {
+			return problems, fmt.Errorf("rewriting history for %s: %w", id, err)
+		}
+		for _, i := range bad {
+			problems[i].Fixed = true
+		}
+	}
+	if unlinked != "" {
+		event := model.Event{Timestamp: time.Now().UTC(), Op: "unlink", From: unlinked, By: user}
+		if err := t.AppendEvent(id, event); err != nil {
+			return problems, err
+		}
+	}
+	return problems, nil
+}
+
+func (t *Tracker) readLogLines() ([]rawLine, error) {
+	f, err := t.Store.OpenLog()
+	if err != nil {
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	lines, err := readRawLines(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading log: %w", err)
+	}
+	return lines, nil
+}
+
+func (t *Tracker) checkLog(lines []rawLine, fix bool) ([]Problem, error) {
+	var problems []Problem
+	var good bytes.Buffer
+	seen := make(map[string]bool)
+	for _, l := range lines {
+		var entry LogEntry
+		if err := json.Unmarshal(l.data, &entry); err != nil {
+			problems = append(problems, Problem{
+				Code:    ProblemBadLogLine,
+				Line:    l.n,
+				Message: fmt.Sprintf("log.jsonl: %v", err),
+				Fixable: true,
+			})
+			continue
+		}
+		if seen[entry.ID] {
+			problems = append(problems, Problem{
+				Code:    ProblemDuplicateLog,
+				IssueID: entry.ID,
+				Line:    l.n,
+				Message: "log.jsonl has more than one entry for this issue",
+				Fixable: true,
+			})
+			continue
+		}
+		seen[entry.ID] = true
+		good.Write(l.data)
+		good.WriteByte('\n')
+	}
+	if !fix || len(problems) == 0 {
+		return problems, nil
+	}
+	if err := t.Store.WriteLog(good.Bytes()); err != nil {
+		return problems, fmt.Errorf("rewriting log: %w", err)
+	}
+	for i := range problems {
+		problems[i].Fixed = true
+	}
+	return problems, nil
+}
@@ -0,0 +1,210 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func problemCodes(problems []Problem) map[string]int {
+	codes := make(map[string]int)
+	for _, p := range problems {
+		codes[p.Code]++
+	}
+	return codes
+}
+
+func TestDoctor_Clean(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := tr.CreateIssue("Fine", "", "", 1, nil, "", "", "testuser"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("problems: got %+v, want none", problems)
+	}
+}
+
+func TestDoctor_ReportsAndFixes(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	parent, err := tr.CreateIssue("Parent", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := tr.CreateIssue("Child", "", "", 1, nil, "", parent.ID, "testuser")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	odd, err := tr.CreateIssue("Odd", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create odd: %v", err)
+	}
+	if _, err := tr.SetStatus(parent.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close parent: %v", err)
+	}
+	if err := tr.PurgeIssue(parent); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if err := tr.AppendLog(parent); err != nil {
+		t.Fatalf("log: %v", err)
+	}
+
+	work := filepath.Join(root, ".work")
+	// A duplicate and a corrupt line in the log.
+	logData, _ := os.ReadFile(filepath.Join(work, "log.jsonl"))
+	logData = append(logData, logData...)
+	logData = append(logData, "{not json\n"...)
+	if err := os.WriteFile(filepath.Join(work, "log.jsonl"), logData, 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	// A corrupt history line on the child and no history on odd.
+	history := filepath.Join(work, "issues", child.ID, "history.jsonl")
+	f, err := os.OpenFile(history, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	_, _ = f.WriteString("{\"ts\":\n")
+	_ = f.Close()
+	if err := os.Remove(filepath.Join(work, "issues", odd.ID, "history.jsonl")); err != nil {
+		t.Fatalf("remove history: %v", err)
+	}
+	// odd's directory no longer matches its id, and it has an unknown status.
+	data := []byte(`{"id":"zzzzzz","title":"Odd","status":"limbo","type":"feature","priority":1,"created":"2024-01-01T00:00:00Z","updated":"2024-01-01T00:00:00Z","revision":1}`)
+	if err := os.WriteFile(filepath.Join(work, "issues", odd.ID, "issue.json"), data, 0o644); err != nil {
+		t.Fatalf("write odd: %v", err)
+	}
+
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	codes := problemCodes(problems)
+	for code, want := range map[string]int{
+		ProblemOrphanParent:   1,
+		ProblemBadEventLine:   1,
+		ProblemMissingHistory: 1,
+		ProblemIDMismatch:     1,
+		ProblemUnknownStatus:  1,
+		ProblemBadLogLine:     1,
+		ProblemDuplicateLog:   1,
+	} {
+		if codes[code] != want {
+			t.Errorf("%s: got %d, want %d (all: %+v)", code, codes[code], want, problems)
+		}
+	}
+
+	if _, err := tr.Doctor(true, "testuser"); err != nil {
+		t.Fatalf("doctor --fix: %v", err)
+	}
+	problems, err = tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor after fix: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Code != ProblemUnknownStatus {
+		t.Errorf("after fix: got %+v, want only the unknown status", problems)
+	}
+	if _, err := tr.ListIssues(); err != nil {
+		t.Errorf("list after fix: %v", err)
+	}
+	if _, err := tr.LoadAllEvents(); err != nil {
+		t.Errorf("events after fix: %v", err)
+	}
+	if _, err := tr.LoadLog(); err != nil {
+		t.Errorf("log after fix: %v", err)
+	}
+	fixed, err := tr.LoadIssue(child.ID)
+	if err != nil {
+		t.Fatalf("load child: %v", err)
+	}
+	if fixed.ParentID != "" {
+		t.Errorf("orphaned child still has parent %q", fixed.ParentID)
+	}
+}
+
+func TestDoctor_BadStateCategory(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.Config.States["blocked"] = model.StateConfig{Category: "stuck"}
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if codes := problemCodes(problems); codes[ProblemBadCategory] != 1 {
+		t.Errorf("problems: got %+v, want one %s", problems, ProblemBadCategory)
+	}
+}
+
+func TestDoctor_BadWorkflow(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.Config.Workflows = map[string]model.Workflow{
+		"chore": {Transitions: map[string][]string{"todo": {"done"}, "done": {"todo"}}},
+		"spike": {Transitions: map[string][]string{"open": {"done"}}},
+	}
+	if _, err := tr.CreateIssue("Tidy", "", "", 3, nil, "chore", "", "testuser"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	// chore starts in the global default state "open", which its
+	// workflow lacks; spike is not a configured type.
+	codes := problemCodes(problems)
+	if codes[ProblemBadWorkflow] != 2 || codes[ProblemUnknownStatus] != 1 {
+		t.Errorf("problems: got %+v", problems)
+	}
+}
+
+func TestDoctor_BadGuard(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.Config.Guards = map[string][]model.Guard{
+		"review": {{Require: model.RequireAssignee}, {Require: "sign-off"}},
+	}
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if codes := problemCodes(problems); codes[ProblemBadGuard] != 1 {
+		t.Errorf("problems: got %+v, want one %s", problems, ProblemBadGuard)
+	}
+}
+
+func TestDoctor_BadAutomationRule(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.Config.Automation = []model.Rule{
+		{When: model.TriggerEnters, State: "active", AssignActor: true},
+		{When: "tuesday", AddLabel: "x"},
+		{When: model.TriggerEnters, AddLabel: "x"},
+		{When: model.TriggerReopened},
+	}
+	problems, err := tr.Doctor(false, "testuser")
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if codes := problemCodes(problems); codes[ProblemBadRule] != 3 {
+		t.Errorf("problems: got %+v, want three %s", problems, ProblemBadRule)
+	}
+}
@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// SaveEdit writes changes to the editable fields of an issue and records
+// an edit event. base is the issue as the caller loaded it and edited is
+// the same issue with the caller's changes applied. If someone else saved
+// the issue in between, non-overlapping changes are merged onto their
+// version. When both sides changed the same field, nothing is written and
+// the stored issue is returned with the conflicting fields.
+//
+// Changing the type to one whose workflow lacks the issue's status fails
+// with a *StateMappingError unless edited also changes the status to map
+// it to. The mapping is recorded as a status event and, like SetStatus,
+// must pass the new state's guards and WIP limit and runs the transition
+// hooks.
+//
+// The automation rules the edit triggers are applied after it.
+func (t *Tracker) SaveEdit(base, edited model.Issue, user string) (model.Issue, []FieldConflict, error) {
+	issue, conflicts, events, err := t.saveEdit(base, edited, user, false)
+	if err != nil || len(conflicts) > 0 {
+		return issue, conflicts, err
+	}
+	t.postTransition(issue, events, user)
+	issue, err = t.automateChange(issue, events, user)
+	return issue, nil, err
+}
+
+// saveEdit is SaveEdit without automation. It also returns the events
+// it recorded. trackerHeld says whether the caller holds the tracker-wide
+// lock.
+func (t *Tracker) saveEdit(base, edited model.Issue, user string, trackerHeld bool) (issue model.Issue, conflicts []FieldConflict, events []model.Event, err error) {
+	err = t.withPreTransition(user, func(approved *hookPlan) error {
+		issue, conflicts, events, err = t.trySaveEdit(base, edited, user, trackerHeld, approved)
+		return err
+	})
+	return issue, conflicts, events, err
+}
+
+// trySaveEdit is one attempt of saveEdit; see withPreTransition.
+func (t *Tracker) trySaveEdit(base, edited model.Issue, user string, trackerHeld bool, approved *hookPlan) (model.Issue, []FieldConflict, []model.Event, error) {
+	fields := ChangedFields(base, edited)
+	if len(fields) == 0 {
+		return base, nil, nil, nil
+	}
+	if edited.Type != base.Type {
+		if err := ValidateType(t.Config, edited.Type); err != nil {
+			return model.Issue{}, nil, nil, err
+		}
+	}
+	mapped := edited.Type != base.Type && edited.Status != base.Status
+	if mapped && !trackerHeld {
+		unlock, held, err := t.lockState(edited.Status)
+		if err != nil {
+			return model.Issue{}, nil, nil, err
+		}
+		defer unlock()
+		trackerHeld = held
+	}
+
+	unlock, err := t.lockIssue(base.ID, trackerHeld)
+	if err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+	defer unlock()
+
+	stored, err := t.LoadIssue(base.ID)
+	if err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+	now := time.Now().UTC()
+	before := base
+	issue := edited
+	if stored.Revision != edited.Revision {
+		merged, conflicts := MergeEdits(base, edited, stored)
+		if len(conflicts) > 0 {
+			return stored, conflicts, nil, nil
+		}
+		// Our mapping only stands if they left the status alone.
+		if mapped && stored.Status == base.Status {
+			merged.Status = edited.Status
+		}
+		before = stored
+		issue = merged
+	}
+	issue.Updated = now
+	if err := t.checkStateMapping(before, issue); err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+	// The mapping moves the issue from the stored status; base may be
+	// stale. It is a status change like any other, guards and hooks
+	// included.
+	var transition *model.Event
+	if mapped && issue.Status != stored.Status {
+		transition = &model.Event{
+			Timestamp: now,
+			Op:        "status",
+			From:      stored.Status,
+			To:        issue.Status,
+			By:        user,
+		}
+		if err := t.beginTransition(issue, []model.Event{*transition}, approved); err != nil {
+			return model.Issue{}, nil, nil, err
+		}
+	}
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+
+	changes, err := FieldChanges(before, issue)
+	if err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+	fields = nil
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	event := model.Event{
+		Timestamp: now,
+		Op:        "edit",
+		Fields:    fields,
+		Changes:   changes,
+		By:        user,
+	}
+	if err := t.AppendEvent(issue.ID, event); err != nil {
+		return model.Issue{}, nil, nil, err
+	}
+	events := []model.Event{event}
+	if transition != nil {
+		if err := t.AppendEvent(issue.ID, *transition); err != nil {
+			return model.Issue{}, nil, nil, err
+		}
+		events = append(events, *transition)
+	}
+	return issue, nil, events, nil
+}
+
+// StateMappingError reports a type change that would leave an issue in a
+// state the new type's workflow does not have.
+type StateMappingError struct {
+	Type   string
+	Status string
+	// States are the states of the new workflow the issue could be
+	// mapped to.
+	States []string
+}
+
+func (e *StateMappingError) Error() string {
+	return fmt.Sprintf("workflow for type %q has no state %q; map it to one of: %s",
+		e.Type, e.Status, strings.Join(e.States, ", "))
+}
+
+// checkStateMapping checks that after's status belongs to the workflow of
+// its type when that differs from before's.
+func (t *Tracker) checkStateMapping(before, after model.Issue) error {
+	if after.Type == before.Type {
+		return nil
+	}
+	w := t.Config.Workflow(after.Type)
+	if w.HasState(after.Status) {
+		return nil
+	}
+	var states []string
+	for _, s := range t.Config.StateNames() {
+		if w.HasState(s) {
+			states = append(states, s)
+		}
+	}
+	return &StateMappingError{Type: after.Type, Status: after.Status, States: states}
+}
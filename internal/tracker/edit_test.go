@@ -0,0 +1,150 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestSaveIssue_RevisionIncrements(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Rev", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if issue.Revision != 1 {
+		t.Errorf("revision after create: got %d, want 1", issue.Revision)
+	}
+	if _, err := tr.SetStatus(issue.ID, "active", "testuser"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	loaded, err := tr.LoadIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Revision != 2 {
+		t.Errorf("revision after status: got %d, want 2", loaded.Revision)
+	}
+}
+
+func TestSaveIssue_RejectsStaleRevision(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Stale", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.AddComment(issue.ID, "newer", "other"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+
+	issue.Title = "Overwrite"
+	err = tr.SaveIssue(issue)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("save stale: got %v, want *ConflictError", err)
+	}
+	if conflict.Expected != 1 || conflict.Current.Revision != 2 {
+		t.Errorf("conflict revisions: expected %d, current %d", conflict.Expected, conflict.Current.Revision)
+	}
+	loaded, err := tr.LoadIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Title != "Stale" || len(loaded.Comments) != 1 {
+		t.Errorf("stale write should not land: got %+v", loaded)
+	}
+}
+
+func TestMergeEdits(t *testing.T) {
+	base := model.Issue{Title: "T", Priority: 2, Labels: []string{"a"}}
+
+	ours := base
+	ours.Title = "Ours"
+	theirs := base
+	theirs.Priority = 1
+	merged, conflicts := MergeEdits(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("disjoint edits: unexpected conflicts %+v", conflicts)
+	}
+	if merged.Title != "Ours" || merged.Priority != 1 {
+		t.Errorf("merged: got title %q priority %d", merged.Title, merged.Priority)
+	}
+
+	theirs.Title = "Theirs"
+	merged, conflicts = MergeEdits(base, ours, theirs)
+	if len(conflicts) != 1 || conflicts[0].Field != "title" {
+		t.Fatalf("conflicts: got %+v, want [title]", conflicts)
+	}
+	if conflicts[0].Ours != "Ours" || conflicts[0].Theirs != "Theirs" {
+		t.Errorf("conflict values: got %+v", conflicts[0])
+	}
+	if merged.Title != "Theirs" {
+		t.Errorf("conflicting field should keep theirs, got %q", merged.Title)
+	}
+}
+
+func TestSaveEdit_ConflictWritesNothing(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	base, err := tr.CreateIssue("Base", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	theirs := base
+	theirs.Title = "Theirs"
+	if _, _, err := tr.SaveEdit(base, theirs, "other"); err != nil {
+		t.Fatalf("their edit: %v", err)
+	}
+
+	ours := base
+	ours.Title = "Ours"
+	current, conflicts, err := tr.SaveEdit(base, ours, "testuser")
+	if err != nil {
+		t.Fatalf("our edit: %v", err)
+	}
+	if len(conflicts) != 1 || current.Title != "Theirs" {
+		t.Errorf("got current %q, conflicts %+v", current.Title, conflicts)
+	}
+	events, err := tr.LoadEvents(base.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("events: got %d, want create + one edit", len(events))
+	}
+}
+
+func TestSaveEdit_RecordsChanges(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	base, err := tr.CreateIssue("Old title", "", "", 2, []string{"a"}, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	edited := base
+	edited.Title = "New title"
+	edited.Priority = 1
+	edited.Labels = []string{"a", "b"}
+	if _, _, err := tr.SaveEdit(base, edited, "testuser"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	events, err := tr.LoadEvents(base.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	ev := events[len(events)-1]
+	want := map[string][2]string{
+		"title":    {`"Old title"`, `"New title"`},
+		"priority": {`2`, `1`},
+		"labels":   {`["a"]`, `["a","b"]`},
+	}
+	if len(ev.Changes) != len(want) {
+		t.Fatalf("changes: got %+v", ev.Changes)
+	}
+	for _, c := range ev.Changes {
+		w, ok := want[c.Field]
+		if !ok || string(c.From) != w[0] || string(c.To) != w[1] {
+			t.Errorf("%s: got %s → %s, want %s → %s", c.Field, c.From, c.To, w[0], w[1])
+		}
+	}
+}
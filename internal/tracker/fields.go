@@ -0,0 +1,180 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// issueField describes one user-editable field of model.Issue.
+type issueField struct {
+	name   string
+	format func(model.Issue) string
+	equal  func(a, b model.Issue) bool
+	copy   func(dst *model.Issue, src model.Issue)
+	// ptr returns a pointer to the field, for encoding and decoding its
+	// value in events.
+	ptr func(i *model.Issue) any
+}
+
+// editableFields lists the fields changed by `work edit`, in the order
+// they are reported.
+var editableFields = []issueField{
+	{
+		name:   "title",
+		format: func(i model.Issue) string { return i.Title },
+		equal:  func(a, b model.Issue) bool { return a.Title == b.Title },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Title = src.Title },
+		ptr:    func(i *model.Issue) any { return &i.Title },
+	},
+	{
+		name:   "description",
+		format: func(i model.Issue) string { return i.Description },
+		equal:  func(a, b model.Issue) bool { return a.Description == b.Description },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Description = src.Description },
+		ptr:    func(i *model.Issue) any { return &i.Description },
+	},
+	{
+		name:   "type",
+		format: func(i model.Issue) string { return i.Type },
+		equal:  func(a, b model.Issue) bool { return a.Type == b.Type },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Type = src.Type },
+		ptr:    func(i *model.Issue) any { return &i.Type },
+	},
+	{
+		name:   "assignee",
+		format: func(i model.Issue) string { return i.Assignee },
+		equal:  func(a, b model.Issue) bool { return a.Assignee == b.Assignee },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Assignee = src.Assignee },
+		ptr:    func(i *model.Issue) any { return &i.Assignee },
+	},
+	{
+		name:   "priority",
+		format: func(i model.Issue) string { return strconv.Itoa(i.Priority) },
+		equal:  func(a, b model.Issue) bool { return a.Priority == b.Priority },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Priority = src.Priority },
+		ptr:    func(i *model.Issue) any { return &i.Priority },
+	},
+	{
+		name:   "labels",
+		format: func(i model.Issue) string { return strings.Join(i.Labels, ", ") },
+		equal: func(a, b model.Issue) bool {
+			return (len(a.Labels) == 0 && len(b.Labels) == 0) || slices.Equal(a.Labels, b.Labels)
+		},
+		copy: func(dst *model.Issue, src model.Issue) { dst.Labels = slices.Clone(src.Labels) },
+		ptr:  func(i *model.Issue) any { return &i.Labels },
+	},
+}
+
+// ChangedFields returns the names of editable fields that differ between
+// before and after.
+func ChangedFields(before, after model.Issue) []string {
+	var changed []string
+	for _, f := range editableFields {
+		if !f.equal(before, after) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+// FieldChanges returns the editable fields that differ between before and
+// after, with each side's value encoded as JSON.
+func FieldChanges(before, after model.Issue) ([]model.FieldChange, error) {
+	var changes []model.FieldChange
+	for _, f := range editableFields {
+		if f.equal(before, after) {
+			continue
+		}
+		from, err := json.Marshal(f.ptr(&before))
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.name, err)
+		}
+		to, err := json.Marshal(f.ptr(&after))
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.name, err)
+		}
+		changes = append(changes, model.FieldChange{Field: f.name, From: from, To: to})
+	}
+	return changes, nil
+}
+
+// setField decodes a value recorded in an event into the named field.
+func setField(issue *model.Issue, name string, value json.RawMessage) error {
+	for _, f := range append(slices.Clone(editableFields), workflowFields...) {
+		if f.name != name {
+			continue
+		}
+		if err := json.Unmarshal(value, f.ptr(issue)); err != nil {
+			return fmt.Errorf("decoding %s: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown field %q", name)
+}
+
+// FieldConflict is an editable field changed to different values on both
+// sides of a three-way merge.
+type FieldConflict struct {
+	Field  string
+	Ours   string
+	Theirs string
+}
+
+// SavedValues returns the conflicting fields and the values saved for
+// them, in the form editor.ConflictNotice takes.
+func SavedValues(conflicts []FieldConflict) (fields, saved []string) {
+	for _, c := range conflicts {
+		fields = append(fields, c.Field)
+		saved = append(saved, c.Theirs)
+	}
+	return fields, saved
+}
+
+// MergeEdits replays the editable-field changes made between base and ours
+// on top of theirs. A field changed on both sides to different values is
+// left at theirs and reported as a conflict.
+func MergeEdits(base, ours, theirs model.Issue) (model.Issue, []FieldConflict) {
+	merged := theirs
+	var conflicts []FieldConflict
+	for _, f := range editableFields {
+		if f.equal(base, ours) {
+			continue
+		}
+		if f.equal(base, theirs) {
+			f.copy(&merged, ours)
+			continue
+		}
+		if !f.equal(ours, theirs) {
+			conflicts = append(conflicts, FieldConflict{
+				Field:  f.name,
+				Ours:   f.format(ours),
+				Theirs: f.format(theirs),
+			})
+		}
+	}
+	return merged, conflicts
+}
+
+// workflowFields are the non-editable scalar fields that commands other
+// than `work edit` change.
+var workflowFields = []issueField{
+	{
+		name:   "status",
+		format: func(i model.Issue) string { return i.Status },
+		equal:  func(a, b model.Issue) bool { return a.Status == b.Status },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.Status = src.Status },
+		ptr:    func(i *model.Issue) any { return &i.Status },
+	},
+	{
+		name:   "parent_id",
+		format: func(i model.Issue) string { return i.ParentID },
+		equal:  func(a, b model.Issue) bool { return a.ParentID == b.ParentID },
+		copy:   func(dst *model.Issue, src model.Issue) { dst.ParentID = src.ParentID },
+		ptr:    func(i *model.Issue) any { return &i.ParentID },
+	},
+}
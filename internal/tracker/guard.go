@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// UnmetGuard is a guard an issue failed, with what is missing.
+type UnmetGuard struct {
+	model.Guard
+	Message string
+}
+
+// GuardError reports every guard an issue failed on entering a state.
+type GuardError struct {
+	ID    string
+	To    string
+	Unmet []UnmetGuard
+}
+
+func (e *GuardError) Error() string {
+	lines := make([]string, len(e.Unmet))
+	for i, u := range e.Unmet {
+		lines[i] = "  - " + u.Message
+	}
+	return fmt.Sprintf("cannot move %s to %q:\n%s", e.ID, e.To, strings.Join(lines, "\n"))
+}
+
+// Missing reports whether require is among the unmet guards.
+func (e *GuardError) Missing(require string) bool {
+	for _, u := range e.Unmet {
+		if u.Require == require {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckGuards evaluates the guards configured for entering state to
+// against issue. It returns a *GuardError listing each unmet guard, or
+// nil when all are met.
+func (t *Tracker) CheckGuards(issue model.Issue, to string) error {
+	var unmet []UnmetGuard
+	for _, g := range t.Config.Guards[to] {
+		msg, err := t.checkGuard(issue, g)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			unmet = append(unmet, UnmetGuard{Guard: g, Message: msg})
+		}
+	}
+	if len(unmet) == 0 {
+		return nil
+	}
+	return &GuardError{ID: issue.ID, To: to, Unmet: unmet}
+}
+
+// checkGuard returns why issue fails g, or "" when it passes.
+func (t *Tracker) checkGuard(issue model.Issue, g model.Guard) (string, error) {
+	switch g.Require {
+	case model.RequireAssignee:
+		if issue.Assignee == "" {
+			return "needs an assignee", nil
+		}
+	case model.RequirePriority:
+		if issue.Priority < g.Min || (g.Max > 0 && issue.Priority > g.Max) {
+			if g.Max > 0 {
+				return fmt.Sprintf("needs a priority from %d to %d (is %d)", g.Min, g.Max, issue.Priority), nil
+			}
+			return fmt.Sprintf("needs a priority of at least %d (is %d)", g.Min, issue.Priority), nil
+		}
+	case model.RequireComment:
+		since, err := t.lastStatusChange(issue)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range issue.Comments {
+			if !c.Created.Before(since) {
+				return "", nil
+			}
+		}
+		return "needs a comment giving the reason", nil
+	case model.RequireChildrenTerminal:
+		issues, err := t.ListSummaries()
+		if err != nil {
+			return "", err
+		}
+		var open []string
+		for _, child := range issues {
+			if child.ParentID == issue.ID && !t.Config.IsTerminal(child.Status) {
+				open = append(open, child.ID)
+			}
+		}
+		if len(open) > 0 {
+			return fmt.Sprintf("needs every child finished (open: %s)", strings.Join(open, ", ")), nil
+		}
+	default:
+		return fmt.Sprintf("unknown guard %q", g.Require), nil
+	}
+	return "", nil
+}
+
+// lastStatusChange returns when issue entered its current state, or when
+// it was created if its status never changed.
+func (t *Tracker) lastStatusChange(issue model.Issue) (time.Time, error) {
+	events, err := t.LoadEvents(issue.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	since := issue.Created
+	for _, ev := range events {
+		if ev.Op == "status" {
+			since = ev.Timestamp
+		}
+	}
+	return since, nil
+}
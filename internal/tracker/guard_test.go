@@ -0,0 +1,78 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestSetStatus_Guards(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.Guards = map[string][]model.Guard{
+		"active":    {{Require: model.RequirePriority, Min: 1, Max: 3}, {Require: model.RequireAssignee}},
+		"done":      {{Require: model.RequireChildrenTerminal}},
+		"cancelled": {{Require: model.RequireComment}},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	parent, err := tr.CreateIssue("Parent", "", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, err = tr.SetStatus(parent.ID, "active", "testuser")
+	var guardErr *GuardError
+	if !errors.As(err, &guardErr) {
+		t.Fatalf("start: got %v, want GuardError", err)
+	}
+	if len(guardErr.Unmet) != 2 || !guardErr.Missing(model.RequirePriority) || !guardErr.Missing(model.RequireAssignee) {
+		t.Errorf("unmet = %+v, want priority and assignee", guardErr.Unmet)
+	}
+	if issue, _ := tr.LoadIssue(parent.ID); issue.Status != "open" {
+		t.Errorf("status = %q after refused transition", issue.Status)
+	}
+
+	edited := parent
+	edited.Priority = 2
+	edited.Assignee = "alice"
+	if _, _, err := tr.SaveEdit(parent, edited, "testuser"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, err := tr.SetStatus(parent.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start with priority and assignee: %v", err)
+	}
+
+	child, err := tr.CreateIssue("Child", "", "", 0, nil, "", parent.ID, "testuser")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := tr.SetStatus(parent.ID, "done", "testuser"); !errors.As(err, &guardErr) || !guardErr.Missing(model.RequireChildrenTerminal) {
+		t.Fatalf("close with open child: got %v", err)
+	}
+
+	if _, err := tr.SetStatus(child.ID, "cancelled", "testuser"); !errors.As(err, &guardErr) || !guardErr.Missing(model.RequireComment) {
+		t.Fatalf("cancel without reason: got %v", err)
+	}
+	if _, err := tr.AddComment(child.ID, "early note", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := tr.SetStatus(child.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close child: %v", err)
+	}
+	if _, err := tr.SetStatus(child.ID, "open", "testuser"); err != nil {
+		t.Fatalf("reopen child: %v", err)
+	}
+	// A comment from before the last status change is not a reason.
+	if _, err := tr.SetStatus(child.ID, "cancelled", "testuser"); !errors.As(err, &guardErr) {
+		t.Fatalf("cancel with stale comment: got %v", err)
+	}
+	if _, err := tr.AddComment(child.ID, "duplicate", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := tr.SetStatus(child.ID, "cancelled", "testuser"); err != nil {
+		t.Fatalf("cancel with reason: %v", err)
+	}
+	if _, err := tr.SetStatus(parent.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close parent: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// Hook names. Each is an executable of that name in .work/hooks/.
+// A pre-hook exiting non-zero aborts the operation.
+const (
+	HookPreCreate      = "pre-create"
+	HookPostCreate     = "post-create"
+	HookPreTransition  = "pre-transition"
+	HookPostTransition = "post-transition"
+	HookPostComment    = "post-comment"
+	HookPreGC          = "pre-gc"
+)
+
+// hookTimeout bounds how long a hook may run. Hooks run with no locks
+// held, so a slow hook only holds up its own command.
+const hookTimeout = time.Minute
+
+// maxHookAttempts bounds how often a status change is planned again
+// because the issue changed while its pre-transition hook ran.
+const maxHookAttempts = 3
+
+// hookInput is what a hook reads as JSON on stdin.
+type hookInput struct {
+	Hook  string       `json:"hook"`
+	User  string       `json:"user,omitempty"`
+	Issue *model.Issue `json:"issue,omitempty"`
+	Event *model.Event `json:"event,omitempty"`
+	// Issues are the issues gc is about to remove, for pre-gc.
+	Issues []model.Issue `json:"issues,omitempty"`
+}
+
+// HookError reports a hook that failed or refused an operation.
+type HookError struct {
+	Hook   string
+	Err    error
+	Output string
+}
+
+func (e *HookError) Error() string {
+	msg := fmt.Sprintf("%s hook: %v", e.Hook, e.Err)
+	if e.Output != "" {
+		msg += "\n" + e.Output
+	}
+	return msg
+}
+
+func (e *HookError) Unwrap() error { return e.Err }
+
+// hookPath returns the executable for the named hook, or "" if there is
+// none.
+func (t *Tracker) hookPath(name string) string {
+	if t.Root == "" {
+		return ""
+	}
+	path := filepath.Join(t.Root, ".work", "hooks", name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+		return ""
+	}
+	return path
+}
+
+// runHook runs the hook named in in.Hook, if installed, with in on stdin
+// and its fields in WORK_* environment variables. Its output goes to
+// HookOutput. A hook that fails returns a *HookError carrying what it
+// printed.
+func (t *Tracker) runHook(in hookInput) error {
+	path := t.hookPath(in.Hook)
+	if path == "" {
+		return nil
+	}
+	stdin, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encoding %s hook input: %w", in.Hook, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = t.Root
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), hookEnv(t.Root, in)...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if t.HookOutput != nil && err == nil {
+		_, _ = io.Copy(t.HookOutput, &out)
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", hookTimeout)
+		}
+		return &HookError{Hook: in.Hook, Err: err, Output: strings.TrimSpace(out.String())}
+	}
+	return nil
+}
+
+// postHook runs a post-hook. The operation has already happened, so a
+// failure is only reported on HookOutput.
+func (t *Tracker) postHook(in hookInput) {
+	if err := t.runHook(in); err != nil && t.HookOutput != nil {
+		fmt.Fprintf(t.HookOutput, "warning: %v\n", err)
+	}
+}
+
+func hookEnv(root string, in hookInput) []string {
+	env := []string{"WORK_HOOK=" + in.Hook, "WORK_ROOT=" + root}
+	if in.User != "" {
+		env = append(env, "WORK_USER="+in.User)
+	}
+	if in.Issue != nil {
+		env = append(env, "WORK_ISSUE_ID="+in.Issue.ID)
+	}
+	if in.Event != nil && in.Event.Op == "status" {
+		env = append(env, "WORK_FROM="+in.Event.From, "WORK_TO="+in.Event.To)
+	}
+	if len(in.Issues) > 0 {
+		ids := make([]string, len(in.Issues))
+		for i, issue := range in.Issues {
+			ids[i] = issue.ID
+		}
+		env = append(env, "WORK_ISSUE_IDS="+strings.Join(ids, " "))
+	}
+	return env
+}
+
+// hookPlan is a change whose pre-transition hooks have to run before it
+// is made: the issue as it will be saved and its status events. A step
+// holding locks returns it as an error so that the hooks run once the
+// locks are released; see withPreTransition.
+type hookPlan struct {
+	issue  model.Issue
+	events []model.Event
+}
+
+func (p *hookPlan) Error() string { return "pre-transition hook has not run" }
+
+// covers reports whether p's hooks ran for the status changes in events,
+// planned from the same revision of the issue.
+func (p *hookPlan) covers(issue model.Issue, events []model.Event) bool {
+	if p == nil || p.issue.Revision != issue.Revision || len(p.events) != len(events) {
+		return false
+	}
+	for i, ev := range events {
+		if p.events[i].From != ev.From || p.events[i].To != ev.To {
+			return false
+		}
+	}
+	return true
+}
+
+// withPreTransition runs step, which makes a change under locks. When
+// step returns a *hookPlan, the pre-transition hook runs for each of its
+// status changes and step is run again with the plan as approved; the
+// change goes ahead if it still covers what step plans. Running hooks
+// between attempts keeps a slow hook from holding the issue lock, and
+// the tracker-wide one, until other commands time out.
+func (t *Tracker) withPreTransition(user string, step func(approved *hookPlan) error) error {
+	var approved *hookPlan
+	for range maxHookAttempts {
+		err := step(approved)
+		var plan *hookPlan
+		if !errors.As(err, &plan) {
+			return err
+		}
+		for _, event := range plan.events {
+			if err := t.runHook(hookInput{Hook: HookPreTransition, User: user, Issue: &plan.issue, Event: &event}); err != nil {
+				return err
+			}
+		}
+		approved = plan
+	}
+	return fmt.Errorf("issue changed while the %s hook ran, %d times; try again", HookPreTransition, maxHookAttempts)
+}
+
+// PreGC runs the pre-gc hook for the issues gc is about to purge or
+// archive. A non-nil error means gc must not go ahead.
+func (t *Tracker) PreGC(issues []model.Issue) error {
+	return t.runHook(hookInput{Hook: HookPreGC, Issues: issues})
+}
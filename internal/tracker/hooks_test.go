@@ -0,0 +1,229 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func writeHook(t *testing.T, root, name, script string) {
+	t.Helper()
+	dir := filepath.Join(root, ".work", "hooks")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	var output bytes.Buffer
+	tr.HookOutput = &output
+	record := filepath.Join(dir, "record")
+	writeHook(t, dir, HookPostTransition, `echo "$WORK_HOOK $WORK_ISSUE_ID $WORK_FROM $WORK_TO $WORK_USER" >> `+record+"\n")
+	writeHook(t, dir, HookPostComment, "cat > "+record+".json\n")
+	writeHook(t, dir, HookPreTransition, `[ "$WORK_TO" != done ] || { echo "not on a Friday"; exit 1; }`+"\n")
+	writeHook(t, dir, HookPreCreate, `grep -q '"title":"WIP' && { echo "no WIP titles"; exit 1; }; echo created`+"\n")
+
+	if _, err := tr.CreateIssue("WIP thing", "", "", 2, nil, "", "", "testuser"); err == nil || !strings.Contains(err.Error(), "no WIP titles") {
+		t.Fatalf("pre-create: got %v, want refusal", err)
+	}
+	if ids, _ := tr.Store.ListIssueIDs(); len(ids) != 0 {
+		t.Fatalf("refused create left issues %v", ids)
+	}
+	issue, err := tr.CreateIssue("Thing", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.Contains(output.String(), "created") {
+		t.Errorf("hook output = %q", output.String())
+	}
+
+	if _, err := tr.SetStatus(issue.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	_, err = tr.SetStatus(issue.ID, "done", "testuser")
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != HookPreTransition || hookErr.Output != "not on a Friday" {
+		t.Fatalf("close: got %v, want pre-transition refusal", err)
+	}
+	if loaded, _ := tr.LoadIssue(issue.ID); loaded.Status != "active" {
+		t.Errorf("status = %q after refused transition", loaded.Status)
+	}
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("read record: %v", err)
+	}
+	if want := "post-transition " + issue.ID + " open active testuser\n"; string(got) != want {
+		t.Errorf("post-transition saw %q, want %q", got, want)
+	}
+
+	if _, err := tr.AddComment(issue.ID, "hello", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	data, err := os.ReadFile(record + ".json")
+	if err != nil {
+		t.Fatalf("read stdin record: %v", err)
+	}
+	var in hookInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("decode hook stdin: %v", err)
+	}
+	if in.Hook != HookPostComment || in.Issue == nil || in.Issue.ID != issue.ID || in.Event == nil || in.Event.Text != "hello" {
+		t.Errorf("post-comment stdin = %s", data)
+	}
+
+	writeHook(t, dir, HookPreGC, `echo "keeping $WORK_ISSUE_IDS"; exit 1`+"\n")
+	closed, err := tr.SetStatus(issue.ID, "cancelled", "testuser")
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	closed.Updated = closed.Updated.AddDate(0, 0, -60)
+	if err := tr.SaveIssue(closed); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := tr.GarbageCollect(30); !errors.As(err, &hookErr) || hookErr.Output != "keeping "+issue.ID {
+		t.Fatalf("gc: got %v, want pre-gc refusal", err)
+	}
+	if _, err := tr.LoadIssue(issue.ID); err != nil {
+		t.Errorf("refused gc removed the issue: %v", err)
+	}
+}
+
+func TestHooks_UndoAndTypeChange(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.HookOutput = &bytes.Buffer{}
+	tr.Config.Workflows = map[string]model.Workflow{
+		"bug": {Transitions: map[string][]string{"triage": {"fixing"}, "fixing": {"triage"}}, DefaultState: "triage"},
+	}
+	record := filepath.Join(dir, "record")
+	writeHook(t, dir, HookPostTransition, `echo "$WORK_ISSUE_ID $WORK_FROM $WORK_TO" >> `+record+"\n")
+	writeHook(t, dir, HookPreTransition, `[ "$WORK_TO" != fixing ] || { echo "no fixing"; exit 1; }`+"\n")
+
+	issue, err := tr.CreateIssue("Thing", "", "", 2, nil, "feature", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	edited := issue
+	edited.Type, edited.Status = "bug", "fixing"
+	var hookErr *HookError
+	if _, _, err := tr.SaveEdit(issue, edited, "testuser"); !errors.As(err, &hookErr) || hookErr.Hook != HookPreTransition {
+		t.Fatalf("map to fixing: got %v, want pre-transition refusal", err)
+	}
+	edited.Status = "triage"
+	if _, _, err := tr.SaveEdit(issue, edited, "testuser"); err != nil {
+		t.Fatalf("map to triage: %v", err)
+	}
+
+	other, err := tr.CreateIssue("Other", "", "", 2, nil, "feature", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(other.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := tr.UndoIssue(other.ID, 1, "testuser"); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("read record: %v", err)
+	}
+	want := issue.ID + " open triage\n" + other.ID + " open active\n" + other.ID + " active open\n"
+	if string(got) != want {
+		t.Errorf("post-transition saw %q, want %q", got, want)
+	}
+}
+
+func TestHooks_PreTransitionRunsWithoutLocks(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.HookOutput = &bytes.Buffer{}
+	issue, err := tr.CreateIssue("Thing", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	record := filepath.Join(dir, "record")
+	started, proceed := filepath.Join(dir, "started"), filepath.Join(dir, "proceed")
+	writeHook(t, dir, HookPreTransition, "echo run >> "+record+"\ntouch "+started+"\n"+
+		"i=0; while [ ! -e "+proceed+" ] && [ $i -lt 200 ]; do sleep 0.05; i=$((i+1)); done\n")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tr.SetStatus(issue.ID, "active", "testuser")
+		done <- err
+	}()
+	for i := 0; ; i++ {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if i == 200 {
+			t.Fatal("pre-transition hook never ran")
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+
+	other, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	other.LockTimeout = 100 * time.Millisecond
+	unlock, err := other.LockTracker()
+	if err != nil {
+		t.Fatalf("tracker lock while the hook runs: %v", err)
+	}
+	unlock()
+	base, _ := other.LoadIssue(issue.ID)
+	edited := base
+	edited.Title = "Renamed"
+	if _, _, err := other.SaveEdit(base, edited, "testuser"); err != nil {
+		t.Fatalf("edit while the hook runs: %v", err)
+	}
+	if err := os.WriteFile(proceed, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	got, _ := tr.LoadIssue(issue.ID)
+	if got.Status != "active" || got.Title != "Renamed" {
+		t.Errorf("issue = %s/%q, want active/Renamed", got.Status, got.Title)
+	}
+	// The edit changed the issue under the first run, so the hook ran
+	// again on the new revision.
+	if runs, _ := os.ReadFile(record); string(runs) != "run\nrun\n" {
+		t.Errorf("hook runs = %q, want 2", runs)
+	}
+}
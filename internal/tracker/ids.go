@@ -0,0 +1,330 @@
+package tracker
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// ID schemes selectable with id_scheme in config.json.
+const (
+	// IDSchemeRandom draws id_length random Crockford Base32 characters.
+	// It is the default.
+	IDSchemeRandom = "random"
+	// IDSchemeSequential numbers issues 1, 2, 3, ...
+	IDSchemeSequential = "sequential"
+	// IDSchemePrefixed numbers issues with the id_prefix project key,
+	// e.g. API-1, API-2.
+	IDSchemePrefixed = "prefixed"
+	// IDSchemeULID uses 26-character time-sortable IDs in the style of
+	// ULIDs: a 48-bit millisecond timestamp followed by 80 random bits.
+	IDSchemeULID = "ulid"
+)
+
+// maxIDAttempts bounds how many candidate IDs CreateIssue and
+// RehashIssue try before giving up.
+const maxIDAttempts = 20
+
+// crockfordAlphabet is Crockford's Base32 alphabet (lowercase).
+// Excludes i, l, o, u to avoid visual ambiguity.
+const crockfordAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// ValidateIDScheme checks that cfg names a known ID scheme and has the
+// settings that scheme needs.
+func ValidateIDScheme(cfg model.Config) error {
+	switch cfg.IDScheme {
+	case "", IDSchemeRandom:
+		if cfg.IDLength < 1 {
+			return fmt.Errorf("id_length must be at least 1 for the random scheme")
+		}
+	case IDSchemeSequential, IDSchemeULID:
+	case IDSchemePrefixed:
+		if cfg.IDPrefix == "" || strings.ContainsAny(cfg.IDPrefix, "-/\\. ") {
+			return fmt.Errorf("id_prefix must be a non-empty key without '-', '/', '.' or spaces, e.g. API")
+		}
+	default:
+		return fmt.Errorf("unknown id_scheme %q (use %s, %s, %s or %s)",
+			cfg.IDScheme, IDSchemeRandom, IDSchemeSequential, IDSchemePrefixed, IDSchemeULID)
+	}
+	return nil
+}
+
+// GenerateID returns a candidate ID in the configured scheme. It does not
+// check whether the ID is free; see CreateIssue.
+func (t *Tracker) GenerateID() (string, error) {
+	next, err := t.idGenerator()
+	if err != nil {
+		return "", err
+	}
+	return next()
+}
+
+// idGenerator returns a function producing successive candidate IDs.
+// Counting schemes start after the highest number in use, including
+// issues already purged to the log, so that numbers are never reused.
+func (t *Tracker) idGenerator() (func() (string, error), error) {
+	if err := ValidateIDScheme(t.Config); err != nil {
+		return nil, err
+	}
+	switch t.Config.IDScheme {
+	case IDSchemeSequential, IDSchemePrefixed:
+		prefix := ""
+		if t.Config.IDScheme == IDSchemePrefixed {
+			prefix = t.Config.IDPrefix + "-"
+		}
+		n, err := t.highestSequence(prefix)
+		if err != nil {
+			return nil, err
+		}
+		return func() (string, error) {
+			n++
+			return prefix + strconv.Itoa(n), nil
+		}, nil
+	case IDSchemeULID:
+		return func() (string, error) { return newULID(time.Now()) }, nil
+	default:
+		return func() (string, error) { return randomID(t.Config.IDLength) }, nil
+	}
+}
+
+// randomID returns n random Crockford Base32 characters. The alphabet
+// has 32 symbols, which divides 256, so masking a random byte to its low
+// five bits picks each symbol with equal probability.
+func randomID(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating id: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = crockfordAlphabet[b&31]
+	}
+	return string(buf), nil
+}
+
+// newULID encodes the millisecond timestamp of now in 10 Crockford
+// characters followed by 16 random ones, so IDs sort by creation time.
+func newULID(now time.Time) (string, error) {
+	random, err := randomID(16)
+	if err != nil {
+		return "", err
+	}
+	ms := uint64(now.UnixMilli())
+	var ts [10]byte
+	for i := len(ts) - 1; i >= 0; i-- {
+		ts[i] = crockfordAlphabet[ms&31]
+		ms >>= 5
+	}
+	return string(ts[:]) + random, nil
+}
+
+// highestSequence returns the largest n among live and purged IDs of the
+// form prefix+n, or 0 if there are none.
+func (t *Tracker) highestSequence(prefix string) (int, error) {
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return 0, fmt.Errorf("reading issues dir: %w", err)
+	}
+	purged, err := t.purgedIDs()
+	if err != nil {
+		return 0, err
+	}
+	highest := 0
+	for _, id := range append(ids, purged...) {
+		if n, ok := sequenceNumber(id, prefix); ok && n > highest {
+			highest = n
+		}
+	}
+	return highest, nil
+}
+
+// sequenceNumber parses id as prefix followed by a positive decimal.
+func sequenceNumber(id, prefix string) (int, bool) {
+	digits, ok := strings.CutPrefix(id, prefix)
+	if !ok || digits == "" || digits[0] == '0' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	return n, err == nil && n > 0
+}
+
+func (t *Tracker) purgedIDs() ([]string, error) {
+	var ids []string
+	for entry, err := range t.LogEntries() {
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, entry.ID)
+	}
+	return ids, nil
+}
+
+// MatchesIDScheme reports whether id looks like it was generated by the
+// configured scheme. Old hex IDs never match the random scheme, so that
+// rehash still upgrades them, and neither do IDs of another length.
+func MatchesIDScheme(cfg model.Config, id string) bool {
+	switch cfg.IDScheme {
+	case IDSchemeSequential:
+		_, ok := sequenceNumber(id, "")
+		return ok
+	case IDSchemePrefixed:
+		_, ok := sequenceNumber(id, cfg.IDPrefix+"-")
+		return ok
+	case IDSchemeULID:
+		return len(id) == 26 && isCrockford(id)
+	default:
+		return len(id) == cfg.IDLength && isCrockford(id) && !IsHexID(id)
+	}
+}
+
+func isCrockford(id string) bool {
+	for _, c := range id {
+		if !strings.ContainsRune(crockfordAlphabet, c) {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// idTaken reports whether id is used by a live issue or was used by a
+// purged one.
+func (t *Tracker) idTaken(id string, purged map[string]bool) (bool, error) {
+	if purged[id] {
+		return true, nil
+	}
+	_, err := t.Store.ReadIssue(id)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("checking id %s: %w", id, err)
+}
+
+// claimID draws candidates from next until one is free, and returns it
+// locked. The caller must call unlock once the issue has been saved, so
+// that a concurrent create can't claim the same ID in between.
+// trackerHeld says whether the caller holds the tracker-wide lock.
+func (t *Tracker) claimID(next func() (string, error), trackerHeld bool) (id string, unlock func(), err error) {
+	ids, err := t.purgedIDs()
+	if err != nil {
+		return "", nil, err
+	}
+	purged := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		purged[id] = true
+	}
+	for range maxIDAttempts {
+		id, err := next()
+		if err != nil {
+			return "", nil, err
+		}
+		unlock, err := t.lockIssue(id, trackerHeld)
+		if err != nil {
+			return "", nil, err
+		}
+		taken, err := t.idTaken(id, purged)
+		if err != nil || !taken {
+			if err != nil {
+				unlock()
+				return "", nil, err
+			}
+			return id, unlock, nil
+		}
+		unlock()
+	}
+	return "", nil, fmt.Errorf("no free id after %d attempts; increase id_length or change id_scheme", maxIDAttempts)
+}
+
+// IDRename records one issue moved to a new ID by MigrateIDs.
+type IDRename struct {
+	OldID string
+	NewID string
+	Title string
+}
+
+// MigrateIDs switches the tracker to scheme (with prefix for the
+// prefixed scheme), saves the config, and renames every issue whose ID
+// doesn't fit the scheme. Issues are renamed oldest first, so counting
+// schemes number them in creation order. Parent links, the completion
+// log and children's histories follow each rename.
+func (t *Tracker) MigrateIDs(scheme, prefix string) ([]IDRename, error) {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	cfg := t.Config
+	cfg.IDScheme, cfg.IDPrefix = scheme, prefix
+	if err := ValidateIDScheme(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.IDScheme != t.Config.IDScheme || cfg.IDPrefix != t.Config.IDPrefix {
+		t.Config = cfg
+		if err := t.SaveConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	issues, err := t.ListIssues()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Created.Before(issues[j].Created)
+	})
+	next, err := t.idGenerator()
+	if err != nil {
+		return nil, err
+	}
+	var renames []IDRename
+	for _, issue := range issues {
+		if MatchesIDScheme(t.Config, issue.ID) {
+			continue
+		}
+		newID, err := t.rehash(issue.ID, next)
+		if err != nil {
+			return renames, fmt.Errorf("renaming %s: %w", issue.ID, err)
+		}
+		renames = append(renames, IDRename{OldID: issue.ID, NewID: newID, Title: issue.Title})
+	}
+	return renames, nil
+}
+
+// rehash moves oldID to the first free ID drawn from next. Callers hold
+// the tracker-wide lock.
+func (t *Tracker) rehash(oldID string, next func() (string, error)) (string, error) {
+	newID, unlock, err := t.claimID(next, true)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return newID, t.renameIssue(oldID, newID)
+}
+
+// SaveConfig writes t.Config to .work/config.json. Trackers without a
+// Root, such as those over a MemoryStore, keep their config in memory.
+func (t *Tracker) SaveConfig() error {
+	if t.Root == "" {
+		return nil
+	}
+	data, err := json.Marshal(t.Config)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	data = append(data, '\n')
+	if err := writeFileAtomic(filepath.Join(t.Root, ".work"), filepath.Join(t.Root, ".work", "config.json"), data, 0o644); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
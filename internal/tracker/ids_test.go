@@ -0,0 +1,208 @@
+package tracker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestCreateIssue_NeverReusesIDs(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.IDLength = 1
+	tr := New(cfg, NewMemoryStore())
+	seen := make(map[string]bool)
+	for range 10 {
+		issue, err := tr.CreateIssue("Tiny", "", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if seen[issue.ID] {
+			t.Fatalf("id %q reused", issue.ID)
+		}
+		seen[issue.ID] = true
+	}
+}
+
+func TestClaimID_SkipsTakenAndPurged(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	if err := tr.SaveIssue(model.Issue{ID: "aaa", Title: "existing"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := tr.Store.AppendLog([]byte(`{"id":"bbb"}`)); err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	candidates := []string{"aaa", "bbb", "ccc"}
+	next := func() (string, error) {
+		id := candidates[0]
+		candidates = candidates[1:]
+		return id, nil
+	}
+	id, unlock, err := tr.claimID(next, false)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	unlock()
+	if id != "ccc" {
+		t.Errorf("claimed %q, want ccc", id)
+	}
+
+	same := func() (string, error) { return "aaa", nil }
+	if _, _, err := tr.claimID(same, false); err == nil || !strings.Contains(err.Error(), "no free id") {
+		t.Errorf("exhausted: got %v", err)
+	}
+}
+
+func TestGenerateID_Sequential(t *testing.T) {
+	for _, tc := range []struct {
+		scheme, prefix string
+		want           []string
+	}{
+		{IDSchemeSequential, "", []string{"1", "2", "4"}},
+		{IDSchemePrefixed, "API", []string{"API-1", "API-2", "API-4"}},
+	} {
+		cfg := model.DefaultConfig()
+		cfg.IDScheme, cfg.IDPrefix = tc.scheme, tc.prefix
+		tr := New(cfg, NewMemoryStore())
+		var got []string
+		for i := range 2 {
+			issue, err := tr.CreateIssue("Numbered", "", "", 2, nil, "", "", "testuser")
+			if err != nil {
+				t.Fatalf("%s create %d: %v", tc.scheme, i, err)
+			}
+			got = append(got, issue.ID)
+		}
+		// A number used by a purged issue is not handed out again.
+		purged := tc.want[2][:len(tc.want[2])-1] + "3"
+		if err := tr.Store.AppendLog([]byte(`{"id":"` + purged + `"}`)); err != nil {
+			t.Fatalf("log: %v", err)
+		}
+		issue, err := tr.CreateIssue("Numbered", "", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("%s create: %v", tc.scheme, err)
+		}
+		got = append(got, issue.ID)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.scheme, got, tc.want)
+		}
+	}
+}
+
+func TestResolvePrefix_SequentialIDs(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.IDScheme = IDSchemeSequential
+	tr := New(cfg, NewMemoryStore())
+	for i := range 11 {
+		if _, err := tr.CreateIssue("Numbered", "", "", 2, nil, "", "", "testuser"); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+	// 1 is a prefix of 10 and 11, but names an issue of its own.
+	for _, id := range []string{"1", "10", "11"} {
+		got, err := tr.ResolvePrefix(id)
+		if err != nil || got != id {
+			t.Errorf("resolve %s: got %q, %v", id, got, err)
+		}
+	}
+	ids, _ := tr.Store.ListIssueIDs()
+	if short := MinPrefixes(ids); short["1"] != "1" {
+		t.Errorf("shortest prefix of 1: got %q", short["1"])
+	}
+}
+
+func TestNewULID_SortsByTime(t *testing.T) {
+	earlier, err := newULID(time.UnixMilli(1_700_000_000_000))
+	if err != nil {
+		t.Fatalf("ulid: %v", err)
+	}
+	later, err := newULID(time.UnixMilli(1_700_000_000_001))
+	if err != nil {
+		t.Fatalf("ulid: %v", err)
+	}
+	if len(earlier) != 26 || !isCrockford(earlier) {
+		t.Errorf("format: got %q", earlier)
+	}
+	if earlier >= later {
+		t.Errorf("not time-sortable: %q >= %q", earlier, later)
+	}
+	cfg := model.DefaultConfig()
+	cfg.IDScheme = IDSchemeULID
+	if !MatchesIDScheme(cfg, later) {
+		t.Errorf("MatchesIDScheme(%q) = false", later)
+	}
+}
+
+func TestValidateIDScheme(t *testing.T) {
+	cfg := model.DefaultConfig()
+	for _, tc := range []struct {
+		scheme, prefix string
+		ok             bool
+	}{
+		{"", "", true},
+		{IDSchemeSequential, "", true},
+		{IDSchemePrefixed, "API", true},
+		{IDSchemePrefixed, "", false},
+		{IDSchemePrefixed, "A-B", false},
+		{"uuid", "", false},
+	} {
+		cfg.IDScheme, cfg.IDPrefix = tc.scheme, tc.prefix
+		if err := ValidateIDScheme(cfg); (err == nil) != tc.ok {
+			t.Errorf("scheme %q prefix %q: got %v", tc.scheme, tc.prefix, err)
+		}
+	}
+}
+
+func TestMigrateIDs_KeepsReferences(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	parent, err := tr.CreateIssue("Parent", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := tr.CreateIssue("Child", "", "", 2, nil, "", parent.ID, "testuser")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if err := tr.Store.AppendLog([]byte(`{"id":"` + parent.ID + `","title":"Parent"}`)); err != nil {
+		t.Fatalf("log: %v", err)
+	}
+
+	renames, err := tr.MigrateIDs(IDSchemePrefixed, "API")
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(renames) != 2 || renames[0].OldID != parent.ID || renames[0].NewID != "API-1" || renames[1].NewID != "API-2" {
+		t.Fatalf("renames: got %+v", renames)
+	}
+	got, err := tr.LoadIssue("API-2")
+	if err != nil {
+		t.Fatalf("load child: %v", err)
+	}
+	if got.Title != "Child" || got.ParentID != "API-1" {
+		t.Errorf("child after migrate: got %+v", got)
+	}
+	if _, err := tr.LoadIssue(child.ID); err == nil {
+		t.Error("old child id still present")
+	}
+	entries, err := tr.LoadLog()
+	if err != nil || len(entries) != 1 || entries[0].ID != "API-1" {
+		t.Errorf("log after migrate: got %+v, %v", entries, err)
+	}
+	assertReplayMatches(t, tr, "API-2")
+
+	reloaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if reloaded.Config.IDScheme != IDSchemePrefixed || reloaded.Config.IDPrefix != "API" {
+		t.Errorf("config not saved: got %+v", reloaded.Config)
+	}
+	if again, err := reloaded.MigrateIDs(IDSchemePrefixed, "API"); err != nil || len(again) != 0 {
+		t.Errorf("second migrate: got %+v, %v", again, err)
+	}
+}
@@ -0,0 +1,142 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// indexVersion is bumped whenever the index layout or the set of summary
+// fields changes, so that stale indexes are rebuilt rather than misread.
+const indexVersion = 1
+
+// racyWindow is how recently an issue.json may have been written and
+// still be cached. Within it, a second write could land in the same
+// mtime tick with the same size and go unnoticed, so such files are
+// always re-read.
+const racyWindow = 2 * time.Second
+
+// IndexStore is implemented by stores that can keep a derived summary
+// index next to the issues. Stamps change whenever an issue document is
+// rewritten; the index itself is a cache and may be deleted at any time.
+type IndexStore interface {
+	// IssueStamps returns the current stamp of every stored issue.
+	IssueStamps() (map[string]IssueStamp, error)
+	ReadIndex() ([]byte, error)
+	WriteIndex(data []byte) error
+}
+
+// IssueStamp identifies one version of an issue document without
+// reading it.
+type IssueStamp struct {
+	ModTime time.Time `json:"mtime"`
+	Size    int64     `json:"size"`
+}
+
+type indexEntry struct {
+	Stamp IssueStamp  `json:"stamp"`
+	Issue model.Issue `json:"issue"`
+}
+
+type issueIndex struct {
+	Version int                   `json:"version"`
+	Entries map[string]indexEntry `json:"entries"`
+}
+
+// summarize strips an issue down to the fields FilterIssues and
+// SortIssues read.
+func summarize(issue model.Issue) model.Issue {
+	issue.Description = ""
+	issue.Comments = nil
+	return issue
+}
+
+// ListSummaries returns every issue with Description and Comments left
+// empty, which is all that listing, filtering and sorting need. When the
+// store supports it, summaries come from an index and only issues whose
+// document changed since the last call are re-read.
+func (t *Tracker) ListSummaries() ([]model.Issue, error) {
+	store, ok := t.Store.(IndexStore)
+	if !ok {
+		issues, err := t.ListIssues()
+		for i := range issues {
+			issues[i] = summarize(issues[i])
+		}
+		return issues, err
+	}
+	stamps, err := store.IssueStamps()
+	if err != nil {
+		return nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	idx := readIndex(store)
+
+	ids := slices.Sorted(maps.Keys(stamps))
+	racy := time.Now().Add(-racyWindow)
+	dirty := len(idx.Entries) != len(stamps)
+	next := make(map[string]indexEntry, len(stamps))
+	issues := make([]model.Issue, 0, len(ids))
+	for _, id := range ids {
+		stamp := stamps[id]
+		if entry, ok := idx.Entries[id]; ok && entry.Stamp.ModTime.Equal(stamp.ModTime) && entry.Stamp.Size == stamp.Size {
+			next[id] = entry
+			issues = append(issues, entry.Issue)
+			continue
+		}
+		issue, err := t.LoadIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		summary := summarize(issue)
+		issues = append(issues, summary)
+		dirty = true
+		if stamp.ModTime.Before(racy) {
+			next[id] = indexEntry{Stamp: stamp, Issue: summary}
+		}
+	}
+	if dirty {
+		// The index is only a cache; failing to write it (say, on a
+		// read-only checkout) just means the next call re-reads.
+		_ = writeIndex(store, issueIndex{Version: indexVersion, Entries: next})
+	}
+	return issues, nil
+}
+
+// Reindex discards the summary index and rebuilds it from every
+// issue.json. Returns the number of issues indexed.
+func (t *Tracker) Reindex() (int, error) {
+	store, ok := t.Store.(IndexStore)
+	if !ok {
+		return 0, fmt.Errorf("store does not keep an index")
+	}
+	if err := writeIndex(store, issueIndex{Version: indexVersion}); err != nil {
+		return 0, fmt.Errorf("clearing index: %w", err)
+	}
+	issues, err := t.ListSummaries()
+	if err != nil {
+		return 0, err
+	}
+	return len(issues), nil
+}
+
+// readIndex loads the index, treating a missing, corrupt or outdated one
+// as empty.
+func readIndex(store IndexStore) issueIndex {
+	var idx issueIndex
+	data, err := store.ReadIndex()
+	if err != nil || json.Unmarshal(data, &idx) != nil || idx.Version != indexVersion {
+		return issueIndex{Version: indexVersion}
+	}
+	return idx
+}
+
+func writeIndex(store IndexStore, idx issueIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return store.WriteIndex(data)
+}
@@ -0,0 +1,131 @@
+package tracker
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// ageIssue backdates an issue.json past the racy window so that the
+// index will cache it.
+func ageIssue(t *testing.T, root, id string) {
+	t.Helper()
+	old := time.Now().Add(-time.Hour)
+	path := filepath.Join(root, ".work", "issues", id, "issue.json")
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestListSummaries_UsesAndRefreshesIndex(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	a, err := tr.CreateIssue("Alpha", "long description", "", 1, []string{"x"}, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.AddComment(a.ID, "note", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	ageIssue(t, root, a.ID)
+
+	summaries, err := tr.ListSummaries()
+	if err != nil {
+		t.Fatalf("list summaries: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Title != "Alpha" || summaries[0].Description != "" || summaries[0].Comments != nil {
+		t.Fatalf("summaries: got %+v", summaries)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".work", "cache", "index.json")); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+	ignore, err := os.ReadFile(filepath.Join(root, ".work", "cache", ".gitignore"))
+	if err != nil || string(ignore) != "*\n" {
+		t.Errorf("cache .gitignore: got %q, %v", ignore, err)
+	}
+
+	// A cached entry is served without reading issue.json: garbage with
+	// the same size and mtime goes unnoticed.
+	path := filepath.Join(root, ".work", "issues", a.ID, "issue.json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	garbage := bytes.Repeat([]byte("x"), len(data))
+	if err := os.WriteFile(path, garbage, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if _, err := tr.ListSummaries(); err != nil {
+		t.Fatalf("cached list: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	// Changes to an issue or the set of issues invalidate the index.
+	if _, err := tr.SetStatus(a.ID, "active", "testuser"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	b, err := tr.CreateIssue("Beta", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	summaries, err = tr.ListSummaries()
+	if err != nil {
+		t.Fatalf("list summaries: %v", err)
+	}
+	got := map[string]string{}
+	for _, s := range summaries {
+		got[s.ID] = s.Status
+	}
+	if got[a.ID] != "active" || got[b.ID] != "open" || len(got) != 2 {
+		t.Errorf("after changes: got %v", got)
+	}
+}
+
+func TestListSummaries_IgnoresCorruptIndex(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := tr.CreateIssue("Alpha", "", "", 1, nil, "", "", "testuser"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".work", "cache"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".work", "cache", "index.json"), []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	summaries, err := tr.ListSummaries()
+	if err != nil || len(summaries) != 1 {
+		t.Fatalf("list summaries: got %d, %v", len(summaries), err)
+	}
+	n, err := tr.Reindex()
+	if err != nil || n != 1 {
+		t.Errorf("reindex: got %d, %v", n, err)
+	}
+}
+
+func TestListSummaries_MemoryStore(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	if _, err := tr.CreateIssue("Alpha", "desc", "", 1, nil, "", "", "testuser"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	summaries, err := tr.ListSummaries()
+	if err != nil || len(summaries) != 1 || summaries[0].Description != "" {
+		t.Errorf("list summaries: got %+v, %v", summaries, err)
+	}
+}
@@ -0,0 +1,155 @@
+package tracker
+
+import (
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func (t *Tracker) workers() int {
+	if t.Workers > 0 {
+		return t.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// loadConcurrently yields load(id) for each id, in the order of ids, while
+// up to workers loads run ahead in the background. Stopping early cancels
+// the loads not yet started and waits for those in flight.
+func loadConcurrently[T any](ids []string, workers int, load func(string) (T, error)) iter.Seq2[T, error] {
+	type result struct {
+		v   T
+		err error
+	}
+	type job struct {
+		id  string
+		out chan result
+	}
+	return func(yield func(T, error) bool) {
+		jobs := make(chan job)
+		// pending holds each job's result channel in submission order;
+		// its capacity bounds how far loading runs ahead of the caller.
+		pending := make(chan chan result, workers)
+		done := make(chan struct{})
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() {
+				for j := range jobs {
+					v, err := load(j.id)
+					j.out <- result{v, err}
+				}
+			})
+		}
+		wg.Go(func() {
+			defer close(jobs)
+			defer close(pending)
+			for _, id := range ids {
+				out := make(chan result, 1)
+				select {
+				case pending <- out:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- job{id, out}:
+				case <-done:
+					return
+				}
+			}
+		})
+		defer wg.Wait()
+		defer close(done)
+
+		for out := range pending {
+			r := <-out
+			if !yield(r.v, r.err) {
+				return
+			}
+		}
+	}
+}
+
+// Issues yields every issue in ID order, loading them concurrently with
+// a bounded number of workers. A load failure is yielded as an error and
+// iteration continues with the next issue unless the caller stops.
+func (t *Tracker) Issues() iter.Seq2[model.Issue, error] {
+	return func(yield func(model.Issue, error) bool) {
+		ids, err := t.Store.ListIssueIDs()
+		if err != nil {
+			yield(model.Issue{}, fmt.Errorf("reading issues dir: %w", err))
+			return
+		}
+		for issue, err := range loadConcurrently(ids, t.workers(), t.LoadIssue) {
+			if !yield(issue, err) {
+				return
+			}
+		}
+	}
+}
+
+// AllEvents yields the events of every issue, annotated with issue ID.
+// Issues are visited in ID order and their histories read concurrently;
+// each issue's events are yielded in the order they were recorded.
+func (t *Tracker) AllEvents() iter.Seq2[EventWithIssue, error] {
+	return func(yield func(EventWithIssue, error) bool) {
+		ids, err := t.Store.ListIssueIDs()
+		if err != nil {
+			yield(EventWithIssue{}, fmt.Errorf("reading issues dir: %w", err))
+			return
+		}
+		load := func(id string) ([]EventWithIssue, error) {
+			events, err := t.LoadEvents(id)
+			if err != nil {
+				return nil, err
+			}
+			annotated := make([]EventWithIssue, len(events))
+			for i, ev := range events {
+				annotated[i] = EventWithIssue{Event: ev, IssueID: id}
+			}
+			return annotated, nil
+		}
+		for events, err := range loadConcurrently(ids, t.workers(), load) {
+			if err != nil {
+				if !yield(EventWithIssue{}, err) {
+					return
+				}
+				continue
+			}
+			for _, ev := range events {
+				if !yield(ev, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// LogEntries yields the completion log one entry at a time as it is
+// read, so callers can start output before the whole log is parsed.
+func (t *Tracker) LogEntries() iter.Seq2[LogEntry, error] {
+	return t.logEntries(t.Strict)
+}
+
+func (t *Tracker) logEntries(strict bool) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		f, err := t.Store.OpenLog()
+		if err != nil {
+			yield(LogEntry{}, fmt.Errorf("opening log: %w", err))
+			return
+		}
+		defer func() { _ = f.Close() }()
+		warn := func(w LineWarning) { t.warn([]LineWarning{w}) }
+		for entry, err := range decodeSeq[LogEntry](f, "log.jsonl", strict, warn) {
+			if err != nil {
+				err = fmt.Errorf("reading log: %w", err)
+			}
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
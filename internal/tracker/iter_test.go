@@ -0,0 +1,209 @@
+package tracker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestIssues_OrderedAndConcurrent(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	tr.Workers = 3
+	var want []string
+	for i := range 50 {
+		issue, err := tr.CreateIssue(fmt.Sprintf("Issue %d", i), "", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		want = append(want, issue.ID)
+	}
+	slices.Sort(want)
+
+	var got []string
+	for issue, err := range tr.Issues() {
+		if err != nil {
+			t.Fatalf("issues: %v", err)
+		}
+		got = append(got, issue.ID)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("order: got %v, want %v", got, want)
+	}
+
+	n := 0
+	for ev, err := range tr.AllEvents() {
+		if err != nil {
+			t.Fatalf("events: %v", err)
+		}
+		if ev.Op != "create" {
+			t.Errorf("event op: got %q", ev.Op)
+		}
+		n++
+	}
+	if n != len(want) {
+		t.Errorf("events: got %d, want %d", n, len(want))
+	}
+}
+
+func TestIssues_EarlyStop(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	tr.Workers = 2
+	for i := range 20 {
+		if _, err := tr.CreateIssue(fmt.Sprintf("Issue %d", i), "", "", 2, nil, "", "", "testuser"); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	done := make(chan int)
+	go func() {
+		n := 0
+		for range tr.Issues() {
+			n++
+			if n == 3 {
+				break
+			}
+		}
+		done <- n
+	}()
+	select {
+	case n := <-done:
+		if n != 3 {
+			t.Errorf("yielded %d, want 3", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("iteration did not stop")
+	}
+}
+
+func TestIssues_YieldsErrorsAndContinues(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	for _, title := range []string{"One", "Two"} {
+		if _, err := tr.CreateIssue(title, "", "", 2, nil, "", "", "testuser"); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".work", "issues", "broken"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	loaded, failed := 0, 0
+	for _, err := range tr.Issues() {
+		if err != nil {
+			failed++
+			continue
+		}
+		loaded++
+	}
+	if loaded != 2 || failed != 1 {
+		t.Errorf("got %d loaded, %d failed; want 2, 1", loaded, failed)
+	}
+	if _, err := tr.ListIssues(); err == nil {
+		t.Error("ListIssues: expected error for broken issue")
+	}
+}
+
+func TestLogEntries_Streams(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	for _, id := range []string{"aaa", "bbb", "ccc"} {
+		if err := tr.Store.AppendLog([]byte(`{"id":"` + id + `","status":"done"}`)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	var got []string
+	for entry, err := range tr.LogEntries() {
+		if err != nil {
+			t.Fatalf("log: %v", err)
+		}
+		got = append(got, entry.ID)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []string{"aaa", "bbb"}) {
+		t.Errorf("entries: got %v", got)
+	}
+}
+
+// seedIssues writes n synthetic issues, each with a create event. Their
+// files are backdated so that the summary index will cache them.
+func seedIssues(b *testing.B, n int) *Tracker {
+	b.Helper()
+	tr, err := Init(b.TempDir())
+	if err != nil {
+		b.Fatalf("init: %v", err)
+	}
+	now := time.Now().UTC()
+	for i := range n {
+		id := fmt.Sprintf("%07d", i)
+		issue := model.Issue{
+			ID: id, Title: fmt.Sprintf("Synthetic issue %d", i), Status: "open",
+			Type: "feature", Priority: i % 4, Labels: []string{"bench"},
+			Created: now, Updated: now,
+		}
+		if err := tr.SaveIssue(issue); err != nil {
+			b.Fatalf("save: %v", err)
+		}
+		if err := tr.AppendEvent(id, model.Event{Timestamp: now, Op: "create", By: "bench", Issue: &issue}); err != nil {
+			b.Fatalf("event: %v", err)
+		}
+		old := now.Add(-time.Hour)
+		if err := os.Chtimes(filepath.Join(tr.Root, ".work", "issues", id, "issue.json"), old, old); err != nil {
+			b.Fatalf("chtimes: %v", err)
+		}
+	}
+	return tr
+}
+
+func benchmarkSizes(b *testing.B, run func(b *testing.B, tr *Tracker)) {
+	for _, n := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("issues=%d", n), func(b *testing.B) {
+			tr := seedIssues(b, n)
+			b.ResetTimer()
+			for b.Loop() {
+				run(b, tr)
+			}
+		})
+	}
+}
+
+func BenchmarkListIssues(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, tr *Tracker) {
+		if _, err := tr.ListIssues(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkListIssues_Sequential(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, tr *Tracker) {
+		tr.Workers = 1
+		if _, err := tr.ListIssues(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkListSummaries(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, tr *Tracker) {
+		if _, err := tr.ListSummaries(); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkAllEvents(b *testing.B) {
+	benchmarkSizes(b, func(b *testing.B, tr *Tracker) {
+		for _, err := range tr.AllEvents() {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package tracker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// LineWarning describes a JSONL line skipped by a lenient read.
+type LineWarning struct {
+	File string // path relative to .work/
+	Line int
+	Err  error
+}
+
+func (w LineWarning) String() string {
+	return fmt.Sprintf("%s:%d: skipped unreadable line: %v", w.File, w.Line, w.Err)
+}
+
+// rawLine is one non-blank line of a JSONL stream and its 1-based number.
+type rawLine struct {
+	n    int
+	data []byte
+}
+
+// readRawLines splits a JSONL stream into lines. Unlike bufio.Scanner it
+// has no line length limit, so very long comment events survive.
+func readRawLines(r io.Reader) ([]rawLine, error) {
+	var lines []rawLine
+	err := scanRawLines(r, func(l rawLine) bool {
+		lines = append(lines, l)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// scanRawLines calls fn for each line of a JSONL stream as it is read,
+// stopping early if fn returns false.
+func scanRawLines(r io.Reader, fn func(rawLine) bool) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		data, err := br.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			if !fn(rawLine{n: n, data: trimmed}) {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodeLines decodes one T per line of r. In strict mode the first line
+// that fails to decode is returned as an error; otherwise such lines are
+// skipped and returned as warnings naming file.
+func decodeLines[T any](r io.Reader, file string, strict bool) ([]T, []LineWarning, error) {
+	var (
+		items    []T
+		warnings []LineWarning
+	)
+	for item, err := range decodeSeq[T](r, file, strict, func(w LineWarning) { warnings = append(warnings, w) }) {
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+	}
+	return items, warnings, nil
+}
+
+// decodeSeq is the streaming form of decodeLines: it yields each item as
+// its line is read and reports skipped lines to warn.
+func decodeSeq[T any](r io.Reader, file string, strict bool, warn func(LineWarning)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		stopped := false
+		err := scanRawLines(r, func(l rawLine) bool {
+			var item T
+			if err := json.Unmarshal(l.data, &item); err != nil {
+				if strict {
+					stopped = true
+					yield(zero, fmt.Errorf("%s:%d: %w", file, l.n, err))
+					return false
+				}
+				warn(LineWarning{File: file, Line: l.n, Err: err})
+				return true
+			}
+			if !yield(item, nil) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil && !stopped {
+			yield(zero, err)
+		}
+	}
+}
+
+// warn records warnings from a lenient read, ignoring repeats of a line
+// already reported.
+func (t *Tracker) warn(warnings []LineWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	t.warnMu.Lock()
+	defer t.warnMu.Unlock()
+	for _, w := range warnings {
+		dup := false
+		for _, seen := range t.warnings {
+			if seen.File == w.File && seen.Line == w.Line {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			t.warnings = append(t.warnings, w)
+		}
+	}
+}
+
+// Warnings returns the corrupt lines skipped by lenient reads so far.
+func (t *Tracker) Warnings() []LineWarning {
+	t.warnMu.Lock()
+	defer t.warnMu.Unlock()
+	return append([]LineWarning(nil), t.warnings...)
+}
@@ -0,0 +1,151 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestLoadEvents_SkipsCorruptLines(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Corrupt", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	history := filepath.Join(root, ".work", "issues", issue.ID, "history.jsonl")
+	f, err := os.OpenFile(history, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.WriteString("<<<<<<< HEAD\n")
+	_ = f.Close()
+	if _, err := tr.AddComment(issue.ID, "after", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+
+	events, err := tr.LoadEvents(issue.ID)
+	if err != nil {
+		t.Fatalf("lenient load: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("events: got %d, want 2", len(events))
+	}
+	warnings := tr.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("warnings: got %+v, want 1", warnings)
+	}
+	want := "issues/" + issue.ID + "/history.jsonl"
+	if warnings[0].File != want || warnings[0].Line != 2 {
+		t.Errorf("warning: got %s:%d, want %s:2", warnings[0].File, warnings[0].Line, want)
+	}
+
+	// Reading again doesn't repeat the warning.
+	if _, err := tr.LoadAllEvents(); err != nil {
+		t.Fatalf("load all: %v", err)
+	}
+	if n := len(tr.Warnings()); n != 1 {
+		t.Errorf("warnings after reload: got %d, want 1", n)
+	}
+
+	tr.Strict = true
+	if _, err := tr.LoadEvents(issue.ID); err == nil || !strings.Contains(err.Error(), want+":2") {
+		t.Errorf("strict load: got %v, want error naming %s:2", err, want)
+	}
+}
+
+func TestLoadLog_SkipsCorruptLines(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	data := `{"id":"aaa111","title":"A","type":"bug","status":"done"}` + "\n" +
+		`{"id":"bbb` + "\n" +
+		`{"id":"ccc333","title":"C","type":"bug","status":"done"}` + "\n"
+	if err := os.WriteFile(filepath.Join(root, ".work", "log.jsonl"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	entries, err := tr.LoadLog()
+	if err != nil {
+		t.Fatalf("load log: %v", err)
+	}
+	if len(entries) != 2 || entries[1].ID != "ccc333" {
+		t.Errorf("entries: got %+v", entries)
+	}
+	if w := tr.Warnings(); len(w) != 1 || w[0].File != "log.jsonl" || w[0].Line != 2 {
+		t.Errorf("warnings: got %+v", w)
+	}
+}
+
+func TestRewrites_KeepCorruptLines(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Corrupt", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	history := filepath.Join(root, ".work", "issues", issue.ID, "history.jsonl")
+	logPath := filepath.Join(root, ".work", "log.jsonl")
+	for _, path := range []string{history, logPath} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		_, _ = f.WriteString("<<<<<<< HEAD\n")
+		_ = f.Close()
+	}
+	before := map[string][]byte{}
+	for _, path := range []string{history, logPath} {
+		before[path], _ = os.ReadFile(path)
+	}
+
+	if err := tr.CompactIssue(issue.ID); err == nil {
+		t.Error("compact rewrote a history with a corrupt line")
+	}
+	if _, err := tr.DeduplicateLog(); err == nil {
+		t.Error("dedup rewrote a log with a corrupt line")
+	}
+	if err := tr.rewriteLogID(issue.ID, "zzz999"); err == nil {
+		t.Error("rewriteLogID rewrote a log with a corrupt line")
+	}
+	for path, data := range before {
+		if after, _ := os.ReadFile(path); !strings.Contains(string(after), "<<<<<<< HEAD") {
+			t.Errorf("%s lost its corrupt line:\n%s\nwas:\n%s", path, after, data)
+		}
+	}
+}
+
+func TestLoadEvents_LongLine(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Long", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	long := strings.Repeat("x", 200*1024)
+	if err := tr.AppendEvent(issue.ID, model.Event{Op: "comment", Text: long}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err := tr.LoadEvents(issue.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(events) != 2 || events[1].Text != long {
+		t.Errorf("long event not read back intact (got %d events)", len(events))
+	}
+}
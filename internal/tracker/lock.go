@@ -0,0 +1,99 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBusy is returned when a lock cannot be acquired within the tracker's
+// LockTimeout because another process holds it.
+var ErrBusy = errors.New("tracker busy")
+
+// DefaultLockTimeout bounds lock waits when Tracker.LockTimeout is zero.
+const DefaultLockTimeout = 10 * time.Second
+
+const (
+	trackerLockName = "tracker"
+	logLockName     = "log"
+)
+
+func issueLockName(id string) string { return "issue-" + id }
+
+// acquire polls the store for the named lock until it is granted or the
+// tracker's LockTimeout elapses.
+func (t *Tracker) acquire(name string, exclusive bool) (func(), error) {
+	timeout := t.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	delay := 5 * time.Millisecond
+	for {
+		unlock, ok, err := t.Store.TryLock(name, exclusive)
+		if err != nil {
+			return nil, fmt.Errorf("acquiring %s lock: %w", name, err)
+		}
+		if ok {
+			return unlock, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %s lock still held by another process after %s", ErrBusy, name, timeout)
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, 100*time.Millisecond)
+	}
+}
+
+// LockTracker takes the tracker-wide exclusive lock, blocking every other
+// mutation until the returned function is called. It is not reentrant:
+// goroutines sharing a Tracker exclude each other like separate processes
+// do, so code holding it calls variants of the operations it needs that
+// don't lock again.
+func (t *Tracker) LockTracker() (func(), error) {
+	return t.acquire(trackerLockName, true)
+}
+
+// LockIssue takes the exclusive lock for a single issue along with a
+// shared hold on the tracker-wide lock. Callers doing their own
+// load-modify-save must hold it across the whole sequence.
+func (t *Tracker) LockIssue(id string) (func(), error) {
+	return t.lockScoped(issueLockName(id))
+}
+
+// lockIssue is LockIssue for code that may already hold the tracker-wide
+// lock, as trackerHeld says. That hold covers every issue, so nothing
+// more is taken.
+func (t *Tracker) lockIssue(id string, trackerHeld bool) (func(), error) {
+	if trackerHeld {
+		return func() {}, nil
+	}
+	return t.LockIssue(id)
+}
+
+// lockLog serializes writers of the completion log. Like lockIssue, it
+// takes nothing when the caller holds the tracker-wide lock.
+func (t *Tracker) lockLog(trackerHeld bool) (func(), error) {
+	if trackerHeld {
+		return func() {}, nil
+	}
+	return t.lockScoped(logLockName)
+}
+
+// lockScoped takes the named exclusive lock under a shared hold on the
+// tracker-wide lock.
+func (t *Tracker) lockScoped(name string) (func(), error) {
+	unlockTracker, err := t.acquire(trackerLockName, false)
+	if err != nil {
+		return nil, err
+	}
+	unlockScoped, err := t.acquire(name, true)
+	if err != nil {
+		unlockTracker()
+		return nil, err
+	}
+	return func() {
+		unlockScoped()
+		unlockTracker()
+	}, nil
+}
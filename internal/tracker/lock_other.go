@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tracker
+
+// tryFlock always succeeds on platforms without flock(2); locking is
+// advisory and best-effort there.
+func tryFlock(path string, exclusive bool) (func(), bool, error) {
+	return func() {}, true, nil
+}
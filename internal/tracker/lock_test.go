@@ -0,0 +1,202 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestAddComment_ConcurrentTrackers(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Busy issue", "", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Separate Tracker instances open separate lock file descriptors,
+	// so they contend the same way separate processes would.
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			other, err := Load(root)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := other.AddComment(issue.ID, fmt.Sprintf("comment %d", i), "agent"); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("comment: %v", err)
+	}
+
+	loaded, err := tr.LoadIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Comments) != writers {
+		t.Errorf("comments: got %d, want %d", len(loaded.Comments), writers)
+	}
+	events, err := tr.LoadEvents(issue.ID)
+	if err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(events) != writers+1 {
+		t.Errorf("events: got %d, want %d", len(events), writers+1)
+	}
+}
+
+func TestLockTracker_BlocksIssueMutations(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Locked out", "", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	unlock, err := tr.LockTracker()
+	if err != nil {
+		t.Fatalf("lock tracker: %v", err)
+	}
+
+	other, err := Load(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	other.LockTimeout = 50 * time.Millisecond
+	_, err = other.SetStatus(issue.ID, "active", "agent")
+	if !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy, got %v", err)
+	}
+
+	unlock()
+	if _, err := other.SetStatus(issue.ID, "active", "agent"); err != nil {
+		t.Fatalf("set status after unlock: %v", err)
+	}
+}
+
+func TestLockTracker_ExcludesOtherGoroutines(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Shared", "", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	tr.LockTimeout = 50 * time.Millisecond
+
+	unlock, err := tr.LockTracker()
+	if err != nil {
+		t.Fatalf("lock tracker: %v", err)
+	}
+	// The same Tracker used from another goroutine, as the TUI does,
+	// must wait like another process would.
+	errs := make(chan error, 2)
+	go func() {
+		_, err := tr.SetStatus(issue.ID, "active", "agent")
+		errs <- err
+	}()
+	go func() {
+		_, err := tr.LockTracker()
+		errs <- err
+	}()
+	for range 2 {
+		if err := <-errs; !errors.Is(err, ErrBusy) {
+			t.Errorf("while locked: got %v, want ErrBusy", err)
+		}
+	}
+	unlock()
+	if _, err := tr.SetStatus(issue.ID, "active", "agent"); err != nil {
+		t.Fatalf("set status after unlock: %v", err)
+	}
+}
+
+func TestCollectIssues(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.LockTimeout = 50 * time.Millisecond
+	old := time.Now().UTC().AddDate(0, 0, -60)
+	for _, issue := range []model.Issue{
+		{ID: "old001", Title: "Old", Status: "done", Created: old, Updated: old},
+		{ID: "new001", Title: "New", Status: "done", Created: old, Updated: old.AddDate(0, 0, 1)},
+		{ID: "open01", Title: "Open", Status: "open", Created: old, Updated: old},
+	} {
+		if err := tr.SaveIssue(issue); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	// Purging under the tracker-wide lock must not try to take it again.
+	var offered []string
+	purged, _, err := tr.CollectIssues(func(completed []model.Issue) []model.Issue {
+		for _, issue := range completed {
+			offered = append(offered, issue.ID)
+		}
+		return completed[1:]
+	}, false)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if strings.Join(offered, ",") != "new001,old001" || strings.Join(purged, ",") != "old001" {
+		t.Errorf("offered %v, purged %v", offered, purged)
+	}
+	if _, err := tr.LoadIssue("old001"); err == nil {
+		t.Error("old001 still exists")
+	}
+	if ids, _ := tr.purgedIDs(); len(ids) != 1 || ids[0] != "old001" {
+		t.Errorf("log: %v", ids)
+	}
+}
+
+func TestMemoryStore_IssueLockExcludes(t *testing.T) {
+	store := NewMemoryStore()
+	tr := New(model.DefaultConfig(), store)
+	tr.LockTimeout = 20 * time.Millisecond
+
+	unlock, err := tr.LockIssue("abc")
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if _, err := tr.LockIssue("abc"); !errors.Is(err, ErrBusy) {
+		t.Errorf("second lock on same issue: got %v, want ErrBusy", err)
+	}
+	other, err := tr.LockIssue("def")
+	if err != nil {
+		t.Fatalf("lock on different issue should succeed: %v", err)
+	}
+	other()
+	if _, err := tr.LockTracker(); !errors.Is(err, ErrBusy) {
+		t.Errorf("tracker lock while issue held: got %v, want ErrBusy", err)
+	}
+	unlock()
+	release, err := tr.LockTracker()
+	if err != nil {
+		t.Fatalf("tracker lock after release: %v", err)
+	}
+	release()
+}
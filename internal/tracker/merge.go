@@ -0,0 +1,197 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// MergeIssue performs a field-aware three-way merge of an issue changed on
+// two branches. A field changed on one side only takes that side's value;
+// a scalar changed on both sides takes the value from the side with the
+// later Updated time. Comments are unioned by timestamp and author, and
+// labels are unioned except for ones either side removed.
+func MergeIssue(base, ours, theirs model.Issue) model.Issue {
+	later := ours
+	if theirs.Updated.After(ours.Updated) {
+		later = theirs
+	}
+
+	merged := ours
+	for _, f := range append(slices.Clone(editableFields), workflowFields...) {
+		if f.name == "labels" {
+			continue
+		}
+		switch {
+		case f.equal(base, ours):
+			f.copy(&merged, theirs)
+		case f.equal(base, theirs):
+			f.copy(&merged, ours)
+		default:
+			f.copy(&merged, later)
+		}
+	}
+
+	merged.Labels = mergeLabels(base.Labels, ours.Labels, theirs.Labels)
+	merged.Comments = mergeComments(ours.Comments, theirs.Comments)
+	if merged.Created.IsZero() {
+		merged.Created = theirs.Created
+	}
+	if theirs.Updated.After(merged.Updated) {
+		merged.Updated = theirs.Updated
+	}
+	merged.Revision = max(ours.Revision, theirs.Revision) + 1
+	return merged
+}
+
+func mergeLabels(base, ours, theirs []string) []string {
+	removed := make(map[string]bool)
+	for _, l := range base {
+		if !slices.Contains(ours, l) || !slices.Contains(theirs, l) {
+			removed[l] = true
+		}
+	}
+	var merged []string
+	for _, l := range append(slices.Clone(ours), theirs...) {
+		if !removed[l] && !slices.Contains(merged, l) {
+			merged = append(merged, l)
+		}
+	}
+	return merged
+}
+
+func mergeComments(ours, theirs []model.Comment) []model.Comment {
+	type key struct {
+		created time.Time
+		by      string
+	}
+	seen := make(map[key]bool)
+	var merged []model.Comment
+	for _, c := range append(slices.Clone(ours), theirs...) {
+		k := key{c.Created.UTC(), c.By}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		merged = append(merged, c)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Created.Before(merged[j].Created)
+	})
+	return merged
+}
+
+// MergeLines merges two versions of a JSONL file such as history.jsonl or
+// log.jsonl. The result is the de-duplicated union of both sides' lines,
+// minus lines present in base that either side removed (for example by
+// compaction), sorted by each line's "ts" or "closed" time.
+func MergeLines(base, ours, theirs []byte) []byte {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+
+	inBase := make(map[string]bool)
+	for _, l := range baseLines {
+		inBase[l] = true
+	}
+	inOurs := make(map[string]bool)
+	for _, l := range oursLines {
+		inOurs[l] = true
+	}
+	inTheirs := make(map[string]bool)
+	for _, l := range theirsLines {
+		inTheirs[l] = true
+	}
+
+	seen := make(map[string]bool)
+	var merged []string
+	for _, l := range append(oursLines, theirsLines...) {
+		if seen[l] || (inBase[l] && !(inOurs[l] && inTheirs[l])) {
+			continue
+		}
+		seen[l] = true
+		merged = append(merged, l)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return lineTime(merged[i]).Before(lineTime(merged[j]))
+	})
+
+	var buf bytes.Buffer
+	for _, l := range merged {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	for _, l := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(l)) > 0 {
+			lines = append(lines, string(l))
+		}
+	}
+	return lines
+}
+
+// lineTime returns the time a history event or log entry line sorts by.
+// Lines that don't parse sort first.
+func lineTime(line string) time.Time {
+	var v struct {
+		Timestamp time.Time `json:"ts"`
+		Closed    time.Time `json:"closed"`
+	}
+	_ = json.Unmarshal([]byte(line), &v)
+	if !v.Timestamp.IsZero() {
+		return v.Timestamp
+	}
+	return v.Closed
+}
+
+// MergeFile merges three versions of a file under .work/, as given to a
+// git merge driver. Issue documents get a field-aware merge; history and
+// log files get a line union. The kind of file is detected from its
+// contents, since git passes the driver temporary paths.
+func MergeFile(base, ours, theirs []byte) ([]byte, error) {
+	if !isIssueDocument(ours) && !isIssueDocument(theirs) {
+		return MergeLines(base, ours, theirs), nil
+	}
+
+	var issues [3]model.Issue
+	for i, data := range [][]byte{base, ours, theirs} {
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, &issues[i]); err != nil {
+			return nil, fmt.Errorf("parsing issue: %w", err)
+		}
+	}
+	data, err := json.Marshal(MergeIssue(issues[0], issues[1], issues[2]))
+	if err != nil {
+		return nil, fmt.Errorf("marshaling issue: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// isIssueDocument reports whether data is an issue.json document rather
+// than JSONL. Both are single-line JSON objects, so this looks for keys
+// only an issue has: events carry "op" and log entries carry "closed".
+func isIssueDocument(data []byte) bool {
+	lines := splitLines(data)
+	if len(lines) != 1 {
+		return false
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(lines[0]), &keys); err != nil {
+		return false
+	}
+	_, isEvent := keys["op"]
+	_, isLogEntry := keys["closed"]
+	_, hasUpdated := keys["updated"]
+	return hasUpdated && !isEvent && !isLogEntry
+}
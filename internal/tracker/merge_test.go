@@ -0,0 +1,124 @@
+package tracker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestMergeIssue(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := model.Issue{
+		ID: "abc123", Title: "Base", Status: "open", Priority: 2,
+		Labels: []string{"keep", "drop"}, Created: t0, Updated: t0, Revision: 3,
+	}
+
+	ours := base
+	ours.Status = "active"
+	ours.Title = "Ours"
+	ours.Labels = []string{"keep", "ours"}
+	ours.Comments = []model.Comment{{Text: "from ours", Created: t0.Add(2 * time.Hour), By: "a"}}
+	ours.Updated = t0.Add(2 * time.Hour)
+	ours.Revision = 4
+
+	theirs := base
+	theirs.Priority = 1
+	theirs.Title = "Theirs"
+	theirs.Labels = []string{"keep", "drop", "theirs"}
+	theirs.Comments = []model.Comment{{Text: "from theirs", Created: t0.Add(time.Hour), By: "b"}}
+	theirs.Updated = t0.Add(3 * time.Hour)
+	theirs.Revision = 5
+
+	merged := MergeIssue(base, ours, theirs)
+	if merged.Status != "active" {
+		t.Errorf("status: got %q, want one-sided change from ours", merged.Status)
+	}
+	if merged.Priority != 1 {
+		t.Errorf("priority: got %d, want one-sided change from theirs", merged.Priority)
+	}
+	if merged.Title != "Theirs" {
+		t.Errorf("title: got %q, want later update to win", merged.Title)
+	}
+	if strings.Join(merged.Labels, ",") != "keep,ours,theirs" {
+		t.Errorf("labels: got %v", merged.Labels)
+	}
+	if len(merged.Comments) != 2 || merged.Comments[0].Text != "from theirs" {
+		t.Errorf("comments: got %+v", merged.Comments)
+	}
+	if !merged.Updated.Equal(theirs.Updated) {
+		t.Errorf("updated: got %v", merged.Updated)
+	}
+	if merged.Revision != 6 {
+		t.Errorf("revision: got %d, want 6", merged.Revision)
+	}
+}
+
+func TestMergeIssue_DuplicateComment(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := model.Comment{Text: "same", Created: t0, By: "a"}
+	base := model.Issue{ID: "abc123"}
+	ours := model.Issue{ID: "abc123", Comments: []model.Comment{c}}
+	theirs := model.Issue{ID: "abc123", Comments: []model.Comment{c}}
+	if got := MergeIssue(base, ours, theirs).Comments; len(got) != 1 {
+		t.Errorf("comments: got %d, want 1", len(got))
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	base := `{"ts":"2024-01-01T00:00:00Z","op":"create"}
+{"ts":"2024-01-02T00:00:00Z","op":"edit","fields":["title"]}
+`
+	// Ours compacted away the edit and then changed status.
+	ours := `{"ts":"2024-01-01T00:00:00Z","op":"create"}
+{"ts":"2024-01-04T00:00:00Z","op":"status","from":"open","to":"done"}
+`
+	theirs := `{"ts":"2024-01-01T00:00:00Z","op":"create"}
+{"ts":"2024-01-02T00:00:00Z","op":"edit","fields":["title"]}
+{"ts":"2024-01-03T00:00:00Z","op":"comment"}
+`
+	want := `{"ts":"2024-01-01T00:00:00Z","op":"create"}
+{"ts":"2024-01-03T00:00:00Z","op":"comment"}
+{"ts":"2024-01-04T00:00:00Z","op":"status","from":"open","to":"done"}
+`
+	if got := string(MergeLines([]byte(base), []byte(ours), []byte(theirs))); got != want {
+		t.Errorf("merged:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMergeFile_DetectsKind(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := model.Issue{ID: "abc123", Title: "Base", Status: "open", Created: t0, Updated: t0}
+	ours := base
+	ours.Status = "done"
+	theirs := base
+	theirs.Comments = []model.Comment{{Text: "hi", Created: t0, By: "b"}}
+
+	var docs [3][]byte
+	for i, issue := range []model.Issue{base, ours, theirs} {
+		data, _ := json.Marshal(issue)
+		docs[i] = append(data, '\n')
+	}
+	out, err := MergeFile(docs[0], docs[1], docs[2])
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	var merged model.Issue
+	if err := json.Unmarshal(out, &merged); err != nil {
+		t.Fatalf("merged output is not an issue: %v\n%s", err, out)
+	}
+	if merged.Status != "done" || len(merged.Comments) != 1 {
+		t.Errorf("merged issue: got %+v", merged)
+	}
+
+	log := []byte(`{"id":"a","title":"A","type":"bug","status":"done","created":"2024-01-01T00:00:00Z","closed":"2024-01-02T00:00:00Z"}` + "\n")
+	out, err = MergeFile(nil, log, log)
+	if err != nil {
+		t.Fatalf("merge log: %v", err)
+	}
+	if string(out) != string(log) {
+		t.Errorf("merged log: got %q", out)
+	}
+}
@@ -0,0 +1,305 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// Migration upgrades a .work/ tree from schema version Version-1 to
+// Version. Apply returns a description of each change it made, or with
+// dryRun set, each change it would make. Migrations must be idempotent,
+// since an interrupted run is resumed from the last recorded version.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(t *Tracker, dryRun bool) ([]string, error)
+}
+
+// migrations is the ordered registry of schema upgrades. Append new
+// migrations with the next version number; never reorder or remove one.
+var migrations = []Migration{
+	{1, "rewrite issue.json files as compact JSON", migrateCompactJSON},
+	{2, "rename old hex IDs to Crockford Base32", migrateHexIDs},
+	{3, "add merge driver entries to .gitattributes", migrateGitattributes},
+	{4, "declare state categories in config.json", migrateStateCategories},
+	{5, "record initial fields in create events written by older versions", migrateCreateSnapshots},
+}
+
+// SchemaVersion is the .work/ schema version written by this binary.
+var SchemaVersion = migrations[len(migrations)-1].Version
+
+// ErrNewerSchema is returned by Load for a tree written by a newer
+// version of work.
+var ErrNewerSchema = errors.New("tracker was written by a newer version of work")
+
+// checkSchema refuses trees this binary doesn't understand, so that
+// teammates on older versions can't rewrite files in a format they
+// don't know.
+func checkSchema(version int) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("%w: .work/ is at schema version %d, but this binary supports up to %d; upgrade work to continue",
+			ErrNewerSchema, version, SchemaVersion)
+	}
+	return nil
+}
+
+// NeedsMigration reports whether the tree is on an older schema than
+// this binary writes.
+func (t *Tracker) NeedsMigration() bool {
+	return t.Config.SchemaVersion < SchemaVersion
+}
+
+// MigrationResult describes one migration applied (or, in a dry run,
+// pending) by Migrate.
+type MigrationResult struct {
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	Actions     []string `json:"actions,omitempty"`
+}
+
+// Migrate applies every migration newer than the tree's schema version,
+// in order, under the tracker-wide lock. The config records the new
+// version after each step, so an interrupted run resumes where it
+// stopped. With dryRun set, nothing is written and the results list what
+// each pending migration would change.
+func (t *Tracker) Migrate(dryRun bool) ([]MigrationResult, error) {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := checkSchema(t.Config.SchemaVersion); err != nil {
+		return nil, err
+	}
+	var results []MigrationResult
+	for _, m := range migrations {
+		if m.Version <= t.Config.SchemaVersion {
+			continue
+		}
+		actions, err := m.Apply(t, dryRun)
+		if err != nil {
+			return results, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		results = append(results, MigrationResult{Version: m.Version, Description: m.Description, Actions: actions})
+		if dryRun {
+			continue
+		}
+		t.Config.SchemaVersion = m.Version
+		if err := t.SaveConfig(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+func migrateCompactJSON(t *Tracker, dryRun bool) ([]string, error) {
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	var actions []string
+	for _, id := range ids {
+		data, err := t.Store.ReadIssue(id)
+		if err != nil {
+			return actions, fmt.Errorf("reading issue: %w", err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return actions, fmt.Errorf("parsing issue %s: %w", id, err)
+		}
+		if bytes.Equal(bytes.TrimSpace(data), compact.Bytes()) {
+			continue
+		}
+		actions = append(actions, "rewrite issues/"+id+"/issue.json")
+		if dryRun {
+			continue
+		}
+		issue, err := t.LoadIssue(id)
+		if err != nil {
+			return actions, err
+		}
+		if err := t.saveIssue(&issue); err != nil {
+			return actions, fmt.Errorf("rewriting %s: %w", id, err)
+		}
+	}
+	return actions, nil
+}
+
+func migrateHexIDs(t *Tracker, dryRun bool) ([]string, error) {
+	// Other schemes may legitimately produce hex-looking IDs.
+	if t.Config.IDScheme != "" && t.Config.IDScheme != IDSchemeRandom {
+		return nil, nil
+	}
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	// A random Crockford ID is all hex digits by chance (1 in 64 at
+	// length 6), and renaming it would break references to it in
+	// commits and branches. Only trees where most IDs are hex were
+	// written by the old generator; elsewhere `work rehash` remains.
+	hex := 0
+	for _, id := range ids {
+		if IsHexID(id) {
+			hex++
+		}
+	}
+	if hex*2 <= len(ids) {
+		return nil, nil
+	}
+	gen, err := t.idGenerator()
+	if err != nil {
+		return nil, err
+	}
+	// A random Crockford ID is occasionally all hex digits itself; skip
+	// those so a re-run doesn't find them again.
+	next := func() (string, error) {
+		for {
+			id, err := gen()
+			if err != nil || !IsHexID(id) {
+				return id, err
+			}
+		}
+	}
+	var actions []string
+	for _, id := range ids {
+		if !IsHexID(id) {
+			continue
+		}
+		if dryRun {
+			actions = append(actions, "rename "+id)
+			continue
+		}
+		newID, err := t.rehash(id, next)
+		if err != nil {
+			return actions, fmt.Errorf("renaming %s: %w", id, err)
+		}
+		actions = append(actions, fmt.Sprintf("rename %s → %s", id, newID))
+	}
+	return actions, nil
+}
+
+func migrateGitattributes(t *Tracker, dryRun bool) ([]string, error) {
+	if t.Root == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(t.Root, ".gitattributes"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var actions []string
+	for _, line := range gitattributesLines {
+		if !strings.Contains(string(data), line) {
+			actions = append(actions, "add "+line)
+		}
+	}
+	if dryRun || len(actions) == 0 {
+		return actions, nil
+	}
+	if err := writeGitattributes(t.Root); err != nil {
+		return actions, fmt.Errorf("writing .gitattributes: %w", err)
+	}
+	return actions, nil
+}
+
+func migrateStateCategories(t *Tracker, dryRun bool) ([]string, error) {
+	var actions []string
+	states := maps.Clone(t.Config.States)
+	if states == nil {
+		states = make(map[string]model.StateConfig)
+	}
+	for _, name := range t.Config.StateNames() {
+		if _, ok := states[name]; ok {
+			continue
+		}
+		sc := t.Config.State(name)
+		states[name] = sc
+		action := fmt.Sprintf("declare %s as %s", name, sc.Category)
+		if sc.Terminal {
+			action += ", terminal"
+		}
+		actions = append(actions, action)
+	}
+	if dryRun || len(actions) == 0 {
+		return actions, nil
+	}
+	// Migrate saves the config once the step is recorded.
+	t.Config.States = states
+	return actions, nil
+}
+
+func migrateCreateSnapshots(t *Tracker, dryRun bool) ([]string, error) {
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	var actions []string
+	for _, id := range ids {
+		issue, err := t.LoadIssue(id)
+		if err != nil {
+			return actions, err
+		}
+		events, err := t.backfillHistory(issue, dryRun)
+		if err != nil {
+			return actions, err
+		}
+		switch {
+		case events == nil:
+		case events[0].Issue != nil:
+			actions = append(actions, "record initial fields of "+id)
+		default:
+			actions = append(actions, fmt.Sprintf("record fields of %s; its history has no values before %s",
+				id, snapshotTime(events).Format(time.RFC3339)))
+		}
+	}
+	return actions, nil
+}
+
+// backfillHistory records the snapshots missing from the history of
+// issue if its create event was written by an older version without the
+// initial fields; see backfillSnapshot. It returns the updated events,
+// or nil if there was nothing to record. With dryRun set, nothing is
+// written.
+func (t *Tracker) backfillHistory(issue model.Issue, dryRun bool) ([]model.Event, error) {
+	events, err := t.loadEventsForRewrite(issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 || events[0].Op != "create" || events[0].Issue != nil {
+		return nil, nil
+	}
+	if !backfillSnapshot(issue, events) {
+		return nil, nil
+	}
+	if dryRun {
+		return events, nil
+	}
+	data, err := marshalLines(events)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Store.WriteEvents(issue.ID, data); err != nil {
+		return nil, fmt.Errorf("rewriting history of %s: %w", issue.ID, err)
+	}
+	return events, nil
+}
+
+// snapshotTime returns when the latest snapshot in events was recorded.
+func snapshotTime(events []model.Event) time.Time {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Issue != nil {
+			return events[i].Timestamp
+		}
+	}
+	return time.Time{}
+}
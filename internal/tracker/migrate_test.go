@@ -0,0 +1,252 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestInit_WritesCurrentSchema(t *testing.T) {
+	tr, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if tr.Config.SchemaVersion != SchemaVersion {
+		t.Errorf("schema = %d, want %d", tr.Config.SchemaVersion, SchemaVersion)
+	}
+	if tr.NeedsMigration() {
+		t.Error("fresh tracker needs migration")
+	}
+}
+
+func TestLoad_RefusesNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	tr.Config.SchemaVersion = SchemaVersion + 1
+	if err := tr.SaveConfig(); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	_, err = Load(dir)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("load: got %v, want ErrNewerSchema", err)
+	}
+	if !strings.Contains(err.Error(), "upgrade work") {
+		t.Errorf("error %q lacks upgrade hint", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Old", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// Simulate a tree from before schema versioning: indented JSON, a
+	// hex ID, no .gitattributes, no state categories and a create event
+	// without the initial fields.
+	if _, err := tr.rehash(issue.ID, func() (string, error) { return "abc123", nil }); err != nil {
+		t.Fatalf("rehash: %v", err)
+	}
+	legacyHistory := `{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ".work", "issues", "abc123", "history.jsonl"), []byte(legacyHistory), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+	issuePath := filepath.Join(dir, ".work", "issues", "abc123", "issue.json")
+	loaded, err := tr.LoadIssue("abc123")
+	if err != nil {
+		t.Fatalf("load issue: %v", err)
+	}
+	indented, _ := json.MarshalIndent(loaded, "", "  ")
+	if err := os.WriteFile(issuePath, indented, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, ".gitattributes")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	tr.Config.SchemaVersion = 0
+	tr.Config.States = nil
+	if err := tr.SaveConfig(); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	tr, err = Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !tr.NeedsMigration() {
+		t.Fatal("old tree does not need migration")
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		results, err := tr.Migrate(true)
+		if err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		if len(results) != len(migrations) {
+			t.Fatalf("got %d results, want %d", len(results), len(migrations))
+		}
+		for _, r := range results {
+			if len(r.Actions) == 0 {
+				t.Errorf("migration %d: no actions", r.Version)
+			}
+		}
+		after, _ := os.ReadFile(issuePath)
+		if string(after) != string(indented) {
+			t.Error("dry run rewrote issue.json")
+		}
+		if tr.Config.SchemaVersion != 0 {
+			t.Errorf("dry run set schema to %d", tr.Config.SchemaVersion)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		if _, err := tr.Migrate(false); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		reloaded, err := Load(dir)
+		if err != nil {
+			t.Fatalf("reload: %v", err)
+		}
+		if reloaded.NeedsMigration() {
+			t.Errorf("schema = %d after migrate", reloaded.Config.SchemaVersion)
+		}
+		ids, _ := reloaded.Store.ListIssueIDs()
+		if len(ids) != 1 || IsHexID(ids[0]) {
+			t.Errorf("ids = %v, want one non-hex id", ids)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, ".work", "issues", ids[0], "issue.json"))
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if strings.Count(string(data), "\n") != 1 {
+			t.Errorf("issue.json not compact: %s", data)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".gitattributes")); err != nil {
+			t.Errorf(".gitattributes: %v", err)
+		}
+		if sc := reloaded.Config.States["done"]; sc.Category != "done" || !sc.Terminal {
+			t.Errorf("states[done] = %+v after migrate", sc)
+		}
+		if _, err := reloaded.ReplayIssue(ids[0]); err != nil {
+			t.Errorf("replay after migrate: %v", err)
+		}
+
+		results, err := reloaded.Migrate(false)
+		if err != nil || len(results) != 0 {
+			t.Errorf("second migrate = %v, %v; want nothing to do", results, err)
+		}
+	})
+}
+
+func TestMigrateCreateSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	writeLegacy := func(title, status string, comments []model.Comment, history string) string {
+		t.Helper()
+		issue, err := tr.CreateIssue(title, "", "", 2, nil, "", "", "old")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		issue.Status = status
+		issue.Comments = comments
+		if err := tr.SaveIssue(issue); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		path := filepath.Join(dir, ".work", "issues", issue.ID, "history.jsonl")
+		if err := os.WriteFile(path, []byte(history), 0o644); err != nil {
+			t.Fatalf("write history: %v", err)
+		}
+		return issue.ID
+	}
+	commented := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	// Older versions recorded neither a comment's text nor edited values.
+	full := writeLegacy("Full", "active", []model.Comment{{Text: "hi", Created: commented, By: "old"}},
+		`{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}
+{"ts":"2024-01-02T00:00:00Z","op":"status","from":"open","to":"active","by":"old"}
+{"ts":"2024-01-03T00:00:00Z","op":"comment","by":"old"}
+`)
+	partial := writeLegacy("Renamed", "active", nil,
+		`{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}
+{"ts":"2024-01-02T00:00:00Z","op":"edit","fields":["title"],"by":"old"}
+{"ts":"2024-01-03T00:00:00Z","op":"status","from":"open","to":"active","by":"old"}
+`)
+	tr.Config.SchemaVersion = 4
+
+	results, err := tr.Migrate(false)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Actions) != 2 {
+		t.Errorf("results = %+v, want one action per legacy issue", results)
+	}
+
+	for _, id := range []string{full, partial} {
+		replayed, err := tr.ReplayIssue(id)
+		if err != nil {
+			t.Fatalf("replay %s: %v", id, err)
+		}
+		stored, _ := tr.LoadIssue(id)
+		if mismatches, _ := ReplayMismatches(stored, replayed); len(mismatches) != 0 {
+			t.Errorf("replay %s: mismatches %+v", id, mismatches)
+		}
+	}
+
+	got, err := tr.IssueAt(full, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("issue at: %v", err)
+	}
+	if got.Status != "open" || got.Title != "Full" || len(got.Comments) != 0 {
+		t.Errorf("at creation: status %q title %q comments %d", got.Status, got.Title, len(got.Comments))
+	}
+
+	// The title before the edit is unknown, so history starts there.
+	before := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := tr.IssueAt(partial, before); !errors.Is(err, ErrIncompleteHistory) {
+		t.Errorf("before legacy edit: got %v, want ErrIncompleteHistory", err)
+	}
+	issues, incomplete, err := tr.IssuesAt(before)
+	if err != nil {
+		t.Fatalf("issues at: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != full || len(incomplete) != 1 || incomplete[0] != partial {
+		t.Errorf("issues at = %d issues, incomplete %v", len(issues), incomplete)
+	}
+	got, err = tr.IssueAt(partial, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil || got.Title != "Renamed" || got.Status != "open" {
+		t.Errorf("after legacy edit: got %+v, %v", got, err)
+	}
+}
+
+func TestMigrateHexIDs_KeepsCrockfordIDs(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	// Random Crockford IDs are sometimes all hex digits by chance.
+	for _, id := range []string{"567d8e", "k3mq7v", "x9wz2r"} {
+		issue := model.Issue{ID: id, Title: id, Status: "open", Type: "feature"}
+		if err := tr.SaveIssue(issue); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	actions, err := migrateHexIDs(tr, false)
+	if err != nil || len(actions) != 0 {
+		t.Errorf("migrate = %q, %v; want nothing renamed", actions, err)
+	}
+	if _, err := tr.LoadIssue("567d8e"); err != nil {
+		t.Errorf("hex-looking Crockford ID was renamed: %v", err)
+	}
+}
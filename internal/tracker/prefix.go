@@ -0,0 +1,196 @@
+package tracker
+
+import (
+	"slices"
+	"strings"
+)
+
+// PrefixTree indexes issue IDs by prefix. It resolves a prefix to the
+// IDs it matches and computes shortest unique prefixes without comparing
+// every pair of IDs. The zero value is an empty tree.
+type PrefixTree struct {
+	root prefixNode
+}
+
+type prefixNode struct {
+	children map[byte]*prefixNode
+	// count is the number of IDs at or below this node.
+	count int
+	// end marks a node where an ID ends.
+	end bool
+}
+
+// NewPrefixTree returns a tree holding ids. Duplicates are ignored.
+func NewPrefixTree(ids []string) *PrefixTree {
+	p := &PrefixTree{}
+	for _, id := range ids {
+		p.Insert(id)
+	}
+	return p
+}
+
+// Len returns the number of IDs in the tree.
+func (p *PrefixTree) Len() int {
+	return p.root.count
+}
+
+// Contains reports whether id is in the tree.
+func (p *PrefixTree) Contains(id string) bool {
+	n := p.find(id)
+	return n != nil && n.end
+}
+
+// Insert adds id to the tree. Returns false if it was already present.
+func (p *PrefixTree) Insert(id string) bool {
+	if p.Contains(id) {
+		return false
+	}
+	n := &p.root
+	n.count++
+	for i := 0; i < len(id); i++ {
+		if n.children == nil {
+			n.children = make(map[byte]*prefixNode)
+		}
+		child, ok := n.children[id[i]]
+		if !ok {
+			child = &prefixNode{}
+			n.children[id[i]] = child
+		}
+		child.count++
+		n = child
+	}
+	n.end = true
+	return true
+}
+
+// Delete removes id from the tree. Returns false if it was not present.
+func (p *PrefixTree) Delete(id string) bool {
+	if !p.Contains(id) {
+		return false
+	}
+	n := &p.root
+	n.count--
+	for i := 0; i < len(id); i++ {
+		child := n.children[id[i]]
+		child.count--
+		if child.count == 0 {
+			delete(n.children, id[i])
+			return true
+		}
+		n = child
+	}
+	n.end = false
+	return true
+}
+
+func (p *PrefixTree) find(prefix string) *prefixNode {
+	n := &p.root
+	for i := 0; i < len(prefix); i++ {
+		child, ok := n.children[prefix[i]]
+		if !ok {
+			return nil
+		}
+		n = child
+	}
+	return n
+}
+
+// Matches returns the IDs starting with prefix, in sorted order.
+func (p *PrefixTree) Matches(prefix string) []string {
+	n := p.find(prefix)
+	if n == nil {
+		return nil
+	}
+	ids := make([]string, 0, n.count)
+	var b strings.Builder
+	b.WriteString(prefix)
+	n.collect(&b, &ids)
+	return ids
+}
+
+// Resolve returns the IDs prefix refers to: prefix alone if it is itself
+// an ID in the tree, so that an ID that is the prefix of others (like
+// sequential ID 1 next to 10) can still be named, else Matches(prefix).
+func (p *PrefixTree) Resolve(prefix string) []string {
+	if p.Contains(prefix) {
+		return []string{prefix}
+	}
+	return p.Matches(prefix)
+}
+
+func (n *prefixNode) collect(b *strings.Builder, ids *[]string) {
+	if n.end {
+		*ids = append(*ids, b.String())
+	}
+	base := b.String()
+	for _, c := range n.sortedKeys() {
+		b.Reset()
+		b.WriteString(base)
+		b.WriteByte(c)
+		n.children[c].collect(b, ids)
+	}
+}
+
+func (n *prefixNode) sortedKeys() []byte {
+	keys := make([]byte, 0, len(n.children))
+	for c := range n.children {
+		keys = append(keys, c)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Shortest returns the shortest prefix of id that no other ID in the
+// tree starts with, or id itself if every shorter prefix is shared.
+// id need not be in the tree.
+func (p *PrefixTree) Shortest(id string) string {
+	self := 0
+	if p.Contains(id) {
+		self = 1
+	}
+	n := &p.root
+	for length := 1; length < len(id); length++ {
+		child, ok := n.children[id[length-1]]
+		if !ok || child.count-self == 0 {
+			return id[:length]
+		}
+		n = child
+	}
+	return id
+}
+
+// ShortestAll returns the shortest unique prefix of every ID in the
+// tree, computed in a single walk.
+func (p *PrefixTree) ShortestAll() map[string]string {
+	result := make(map[string]string, p.root.count)
+	var walk func(n *prefixNode, path []byte)
+	walk = func(n *prefixNode, path []byte) {
+		if n.count == 1 && len(path) > 0 {
+			// Exactly one ID lies below; follow it to its end.
+			prefix := len(path)
+			for !n.end {
+				for c, child := range n.children {
+					path = append(path, c)
+					n = child
+				}
+			}
+			id := string(path)
+			if prefix < len(id) {
+				result[id] = id[:prefix]
+			} else {
+				result[id] = id
+			}
+			return
+		}
+		if n.end {
+			// An ID that is a prefix of others is never unique.
+			id := string(path)
+			result[id] = id
+		}
+		for c, child := range n.children {
+			walk(child, append(path, c))
+		}
+	}
+	walk(&p.root, nil)
+	return result
+}
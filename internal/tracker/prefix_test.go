@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// naiveMinPrefix is the pairwise definition PrefixTree must agree with.
+func naiveMinPrefix(id string, all []string) string {
+	for length := 1; length < len(id); length++ {
+		unique := true
+		for _, other := range all {
+			if other != id && strings.HasPrefix(other, id[:length]) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return id[:length]
+		}
+	}
+	return id
+}
+
+func TestPrefixTree_MatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	var ids []string
+	for range 500 {
+		b := make([]byte, 1+rng.IntN(5))
+		for i := range b {
+			b[i] = "abc"[rng.IntN(3)]
+		}
+		ids = append(ids, string(b))
+	}
+	tree := NewPrefixTree(ids)
+	all := tree.ShortestAll()
+	for _, id := range ids {
+		want := naiveMinPrefix(id, ids)
+		if got := tree.Shortest(id); got != want {
+			t.Errorf("Shortest(%q): got %q, want %q", id, got, want)
+		}
+		if all[id] != want {
+			t.Errorf("ShortestAll[%q]: got %q, want %q", id, all[id], want)
+		}
+	}
+	if got := tree.Shortest("cccccccc"); got != naiveMinPrefix("cccccccc", ids) {
+		t.Errorf("Shortest of absent id: got %q", got)
+	}
+}
+
+func TestPrefixTree_InsertDeleteMatches(t *testing.T) {
+	tree := NewPrefixTree([]string{"a1b2c3", "a1b9zz", "ff0000"})
+	if got := tree.Matches("a1b"); !slices.Equal(got, []string{"a1b2c3", "a1b9zz"}) {
+		t.Errorf("matches a1b: got %v", got)
+	}
+	if got := tree.Shortest("a1b2c3"); got != "a1b2" {
+		t.Errorf("shortest: got %q, want a1b2", got)
+	}
+	if tree.Insert("a1b2c3") {
+		t.Error("duplicate insert reported as new")
+	}
+	if !tree.Delete("a1b9zz") || tree.Delete("a1b9zz") {
+		t.Error("delete: want true then false")
+	}
+	if got := tree.Shortest("a1b2c3"); got != "a" {
+		t.Errorf("shortest after delete: got %q, want a", got)
+	}
+	if tree.Len() != 2 || tree.Contains("a1b9zz") || tree.Matches("a1b9") != nil {
+		t.Errorf("after delete: len %d, matches %v", tree.Len(), tree.Matches("a1b9"))
+	}
+	if got := tree.Matches(""); !slices.Equal(got, []string{"a1b2c3", "ff0000"}) {
+		t.Errorf("matches all: got %v", got)
+	}
+}
+
+func BenchmarkMinPrefixes(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%06x", rand.Uint32()&0xffffff)
+		}
+		b.Run(fmt.Sprintf("ids=%d", n), func(b *testing.B) {
+			for b.Loop() {
+				MinPrefixes(ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// ErrIncompleteHistory is returned when an issue's history lacks the data
+// needed to replay it, typically because it was written by an older
+// version that didn't record field values.
+var ErrIncompleteHistory = errors.New("history is incomplete")
+
+// ErrNotYetCreated is returned by IssueAt when the issue was created after
+// the requested time.
+var ErrNotYetCreated = errors.New("issue did not exist yet")
+
+// ReplayEvents rebuilds the state of issue id by folding its events in
+// order, starting from the latest event that carries a snapshot of the
+// issue: the "create" event with its initial fields, or a later one such
+// as the event kept by compaction or the "restore" of an issue recovered
+// without its history. Events before that snapshot are superseded by it.
+func ReplayEvents(id string, events []model.Event) (model.Issue, error) {
+	if len(events) == 0 {
+		return model.Issue{}, fmt.Errorf("%w: no events", ErrIncompleteHistory)
+	}
+	start := -1
+	for i, ev := range events {
+		if ev.Issue != nil {
+			start = i
+		}
+	}
+	if start < 0 {
+		if events[0].Op == "create" {
+			return model.Issue{}, fmt.Errorf("%w: create has no initial fields; run 'work migrate' to record them", ErrIncompleteHistory)
+		}
+		return model.Issue{}, fmt.Errorf("%w: first event is %q, not create", ErrIncompleteHistory, events[0].Op)
+	}
+	var issue model.Issue
+	for _, ev := range events[start:] {
+		if err := applyEvent(&issue, ev); err != nil {
+			return model.Issue{}, fmt.Errorf("replaying %s at %s: %w", ev.Op, ev.Timestamp.Format(time.RFC3339), err)
+		}
+	}
+	issue.ID = id
+	return issue, nil
+}
+
+func applyEvent(issue *model.Issue, ev model.Event) error {
+	if ev.Issue != nil {
+		// A snapshot supersedes everything before it.
+		*issue = cloneIssue(*ev.Issue)
+		return nil
+	}
+	switch ev.Op {
+	case "create":
+		return fmt.Errorf("%w: create has no initial fields", ErrIncompleteHistory)
+	case "status":
+		issue.Status = ev.To
+	case "edit":
+		if len(ev.Changes) == 0 && len(ev.Fields) > 0 {
+			return fmt.Errorf("%w: edit has no recorded values", ErrIncompleteHistory)
+		}
+		for _, c := range ev.Changes {
+			if err := setField(issue, c.Field, c.To); err != nil {
+				return err
+			}
+		}
+	case "link":
+		issue.ParentID = ev.To
+	case "unlink":
+		issue.ParentID = ""
+	case "comment":
+		if ev.Text == "" {
+			return fmt.Errorf("%w: comment has no text", ErrIncompleteHistory)
+		}
+		issue.Comments = append(issue.Comments, model.Comment{Text: ev.Text, Created: ev.Timestamp, By: ev.By})
+	case "uncomment":
+		for i := len(issue.Comments) - 1; i >= 0; i-- {
+			if issue.Comments[i].Text == ev.Text {
+				issue.Comments = slices.Delete(issue.Comments, i, i+1)
+				break
+			}
+		}
+	case "restore":
+		// Marks the issue coming back from an archive or from git
+		// history; any snapshot it carries was applied above.
+	default:
+		return fmt.Errorf("unknown event op %q", ev.Op)
+	}
+	issue.Updated = ev.Timestamp
+	if ev.Compacted {
+		compactFields(issue)
+	}
+	return nil
+}
+
+// unapplyEvent is the inverse of applyEvent: it turns issue as it was
+// after ev into issue as it was before. A comment event written without
+// its text gets it filled in from the comment it added. It reports false
+// if ev doesn't record enough to be undone, as with edits written by
+// older versions.
+func unapplyEvent(issue *model.Issue, ev *model.Event) bool {
+	switch ev.Op {
+	case "status":
+		if ev.From == "" {
+			return false
+		}
+		issue.Status = ev.From
+	case "edit":
+		if len(ev.Changes) == 0 && len(ev.Fields) > 0 {
+			return false
+		}
+		for _, c := range ev.Changes {
+			if setField(issue, c.Field, c.From) != nil {
+				return false
+			}
+		}
+	case "link":
+		parent, ok := previousParent(*ev)
+		if !ok {
+			return false
+		}
+		issue.ParentID = parent
+	case "unlink":
+		if ev.From == "" {
+			return false
+		}
+		issue.ParentID = ev.From
+	case "comment":
+		idx := slices.IndexFunc(issue.Comments, func(c model.Comment) bool {
+			return c.Created.Equal(ev.Timestamp) && (ev.Text == "" || c.Text == ev.Text)
+		})
+		if idx < 0 {
+			return false
+		}
+		ev.Text = issue.Comments[idx].Text
+		issue.Comments = slices.Delete(issue.Comments, idx, idx+1)
+	case "restore":
+	default:
+		return false
+	}
+	return true
+}
+
+// previousParent returns the parent an issue had before the link event
+// ev, and whether it is known.
+func previousParent(ev model.Event) (string, bool) {
+	return ev.From, ev.From != ""
+}
+
+// backfillSnapshot records a snapshot in a history written before create
+// events carried the initial fields, so that it can be replayed. It works
+// back from issue, the stored state, undoing each event in turn. If it
+// reaches the create event, the initial fields are recorded there;
+// otherwise the state after the last event it could not undo is recorded
+// on that event, and history is replayable from that point on. It
+// reports whether it changed events.
+func backfillSnapshot(issue model.Issue, events []model.Event) bool {
+	state := cloneIssue(issue)
+	state.Revision = 0
+	changed := false
+	for i := len(events) - 1; i >= 0; i-- {
+		ev := &events[i]
+		if ev.Issue != nil {
+			return changed
+		}
+		after := cloneIssue(state)
+		text := ev.Text
+		if i > 0 && unapplyEvent(&state, ev) {
+			changed = changed || ev.Text != text
+			continue
+		}
+		after.Updated = ev.Timestamp
+		ev.Issue = &after
+		return true
+	}
+	return changed
+}
+
+func cloneIssue(issue model.Issue) model.Issue {
+	issue.Labels = slices.Clone(issue.Labels)
+	issue.Comments = slices.Clone(issue.Comments)
+	return issue
+}
+
+// ReplayIssue rebuilds an issue from its history.jsonl alone.
+func (t *Tracker) ReplayIssue(id string) (model.Issue, error) {
+	events, err := t.LoadEvents(id)
+	if err != nil {
+		return model.Issue{}, err
+	}
+	return ReplayEvents(id, events)
+}
+
+// IssueAt reconstructs issue id as it was at time at, by folding the
+// events in its history up to and including that moment. It fails with
+// ErrIncompleteHistory if at falls before the point history was
+// compacted.
+func (t *Tracker) IssueAt(id string, at time.Time) (model.Issue, error) {
+	events, err := t.LoadEvents(id)
+	if err != nil {
+		return model.Issue{}, err
+	}
+	n := 0
+	for n < len(events) && !events[n].Timestamp.After(at) {
+		n++
+	}
+	if n == 0 {
+		return model.Issue{}, fmt.Errorf("%w at %s", ErrNotYetCreated, at.Format(time.RFC3339))
+	}
+	for _, ev := range events[n:] {
+		// Compaction keeps the create event, so what is left before it
+		// replays without error but skips every change in between.
+		if ev.Compacted {
+			return model.Issue{}, fmt.Errorf("%w: history before %s was compacted",
+				ErrIncompleteHistory, ev.Timestamp.Format(time.RFC3339))
+		}
+	}
+	issue, err := ReplayEvents(id, events[:n])
+	if errors.Is(err, ErrIncompleteHistory) {
+		// A snapshot recorded later means history only starts there.
+		for _, ev := range events[n:] {
+			if ev.Issue != nil {
+				return model.Issue{}, fmt.Errorf("%w: no field values recorded before %s",
+					ErrIncompleteHistory, ev.Timestamp.Format(time.RFC3339))
+			}
+		}
+	}
+	return issue, err
+}
+
+// IssuesAt reconstructs every issue that existed at time at. Issues
+// created later are omitted; issues already purged by gc have no
+// history left and can't be included. Issues whose history doesn't
+// reach back to at are omitted too, and their IDs returned in
+// incomplete.
+func (t *Tracker) IssuesAt(at time.Time) (issues []model.Issue, incomplete []string, err error) {
+	ids, err := t.Store.ListIssueIDs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading issues dir: %w", err)
+	}
+	for _, id := range ids {
+		issue, err := t.IssueAt(id, at)
+		switch {
+		case errors.Is(err, ErrNotYetCreated):
+			continue
+		case errors.Is(err, ErrIncompleteHistory):
+			incomplete = append(incomplete, id)
+			continue
+		case err != nil:
+			return nil, nil, fmt.Errorf("reconstructing %s: %w", id, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, incomplete, nil
+}
+
+// ReplayMismatches compares a stored issue with the state replayed from
+// its history. Each mismatch records the stored value as From and the
+// replayed value as To. Revision and the Updated time are not compared,
+// since saves without a visible change (such as format rewrites) bump
+// them without an event.
+func ReplayMismatches(stored, replayed model.Issue) ([]model.FieldChange, error) {
+	if len(stored.Comments) == 0 {
+		stored.Comments = nil
+	}
+	if len(replayed.Comments) == 0 {
+		replayed.Comments = nil
+	}
+	var mismatches []model.FieldChange
+	for _, f := range append(slices.Clone(editableFields), workflowFields...) {
+		if f.equal(stored, replayed) {
+			continue
+		}
+		from, _ := json.Marshal(f.ptr(&stored))
+		to, _ := json.Marshal(f.ptr(&replayed))
+		mismatches = append(mismatches, model.FieldChange{Field: f.name, From: from, To: to})
+	}
+	for _, extra := range []struct {
+		name     string
+		from, to any
+	}{
+		{"id", stored.ID, replayed.ID},
+		{"created", stored.Created, replayed.Created},
+		{"comments", stored.Comments, replayed.Comments},
+	} {
+		from, err := json.Marshal(extra.from)
+		if err != nil {
+			return nil, err
+		}
+		to, err := json.Marshal(extra.to)
+		if err != nil {
+			return nil, err
+		}
+		if string(from) != string(to) {
+			mismatches = append(mismatches, model.FieldChange{Field: extra.name, From: from, To: to})
+		}
+	}
+	return mismatches, nil
+}
+
+// RebuildIssue replaces issue.json with the state replayed from history
+// and returns what changed.
+func (t *Tracker) RebuildIssue(id string) ([]model.FieldChange, error) {
+	unlock, err := t.LockIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	stored, err := t.LoadIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	replayed, err := t.ReplayIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	mismatches, err := ReplayMismatches(stored, replayed)
+	if err != nil || len(mismatches) == 0 {
+		return nil, err
+	}
+	replayed.Revision = stored.Revision
+	if err := t.saveIssue(&replayed); err != nil {
+		return nil, err
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,213 @@
+package tracker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func assertReplayMatches(t *testing.T, tr *Tracker, id string) {
+	t.Helper()
+	stored, err := tr.LoadIssue(id)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	replayed, err := tr.ReplayIssue(id)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	mismatches, err := ReplayMismatches(stored, replayed)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	for _, m := range mismatches {
+		t.Errorf("%s: stored %s, replayed %s", m.Field, m.From, m.To)
+	}
+}
+
+func TestReplayIssue_MatchesStoredState(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	parent, err := tr.CreateIssue("Parent", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	issue, err := tr.CreateIssue("Title", "line one\nline two", "jim", 2, []string{"a"}, "bug", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	edited := issue
+	edited.Title = "New title"
+	edited.Labels = nil
+	if _, _, err := tr.SaveEdit(issue, edited, "testuser"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "active", "testuser"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if _, err := tr.AddComment(issue.ID, "first", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := tr.AddComment(issue.ID, "second", "other"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := tr.LinkIssue(issue.ID, parent.ID, "testuser"); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if _, err := tr.UndoIssue(issue.ID, 2, "testuser"); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	assertReplayMatches(t, tr, issue.ID)
+
+	newParent, err := tr.RehashIssue(parent.ID)
+	if err != nil {
+		t.Fatalf("rehash: %v", err)
+	}
+	if _, err := tr.LinkIssue(issue.ID, newParent, "testuser"); err != nil {
+		t.Fatalf("relink: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := tr.CompactIssue(issue.ID); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	assertReplayMatches(t, tr, issue.ID)
+	assertReplayMatches(t, tr, newParent)
+}
+
+func TestRebuildIssue_RepairsTampering(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Honest", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	tampered, _ := tr.LoadIssue(issue.ID)
+	tampered.Title = "Tampered"
+	if err := tr.SaveIssue(tampered); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	mismatches, err := tr.RebuildIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Field != "title" {
+		t.Errorf("mismatches: got %+v", mismatches)
+	}
+	got, _ := tr.LoadIssue(issue.ID)
+	if got.Title != "Honest" {
+		t.Errorf("title after rebuild: got %q", got.Title)
+	}
+	if again, err := tr.RebuildIssue(issue.ID); err != nil || len(again) != 0 {
+		t.Errorf("second rebuild: got %+v, %v", again, err)
+	}
+}
+
+func TestReplayIssue_LegacyHistory(t *testing.T) {
+	root := t.TempDir()
+	tr, err := Init(root)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Legacy", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	history := filepath.Join(root, ".work", "issues", issue.ID, "history.jsonl")
+	legacy := `{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}` + "\n"
+	if err := os.WriteFile(history, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, err = tr.ReplayIssue(issue.ID)
+	if !errors.Is(err, ErrIncompleteHistory) || !strings.Contains(err.Error(), "initial fields") {
+		t.Errorf("replay legacy: got %v, want ErrIncompleteHistory", err)
+	}
+}
+
+func TestIssueAt(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Before", "", "", 2, []string{"a"}, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "active", "testuser"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	mid := time.Now().UTC()
+	edited, _ := tr.LoadIssue(issue.ID)
+	edited.Title = "After"
+	edited.Priority = 1
+	if _, _, err := tr.SaveEdit(issue, edited, "testuser"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	later, err := tr.CreateIssue("Later", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create later: %v", err)
+	}
+
+	got, err := tr.IssueAt(issue.ID, mid)
+	if err != nil {
+		t.Fatalf("issue at: %v", err)
+	}
+	if got.Title != "Before" || got.Priority != 2 || got.Status != "active" {
+		t.Errorf("at mid: got title %q priority %d status %q", got.Title, got.Priority, got.Status)
+	}
+	if _, err := tr.IssueAt(later.ID, mid); !errors.Is(err, ErrNotYetCreated) {
+		t.Errorf("later issue at mid: got %v, want ErrNotYetCreated", err)
+	}
+
+	issues, _, err := tr.IssuesAt(mid)
+	if err != nil {
+		t.Fatalf("issues at: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != issue.ID {
+		t.Errorf("issues at mid: got %+v", issues)
+	}
+	issues, _, err = tr.IssuesAt(time.Now().UTC())
+	if err != nil {
+		t.Fatalf("issues at now: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Errorf("issues at now: got %d, want 2", len(issues))
+	}
+}
+
+func TestIssueAt_Compacted(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Before", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "active", "testuser"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	mid := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	if _, err := tr.SetStatus(issue.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := tr.CompactIssue(issue.ID); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+
+	// Only create and close survive; the start of work in between is gone.
+	if got, err := tr.IssueAt(issue.ID, mid); !errors.Is(err, ErrIncompleteHistory) {
+		t.Errorf("at mid: got %+v, %v; want ErrIncompleteHistory", got, err)
+	}
+	got, err := tr.IssueAt(issue.ID, time.Now().UTC())
+	if err != nil || got.Status != "done" {
+		t.Errorf("after close: got %+v, %v", got, err)
+	}
+	if _, incomplete, err := tr.IssuesAt(mid); err != nil || len(incomplete) != 1 {
+		t.Errorf("issues at mid: incomplete %v, err %v", incomplete, err)
+	}
+}
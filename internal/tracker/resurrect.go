@@ -0,0 +1,174 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// git runs a git command in the tracker root and returns its stdout.
+func (t *Tracker) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", t.Root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// lastCommitWith returns the newest commit reachable from HEAD in which
+// path (relative to the tracker root) exists, or "" if there is none.
+func (t *Tracker) lastCommitWith(path string) (string, error) {
+	out, err := t.git("rev-list", "HEAD", "--", path)
+	if err != nil {
+		return "", err
+	}
+	for _, commit := range strings.Fields(string(out)) {
+		// The newest commit touching path is usually the one that
+		// deleted it; the file still exists in the one before.
+		err := exec.Command("git", "-C", t.Root, "cat-file", "-e", commit+":./"+path).Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			return commit, nil
+		case errors.As(err, &exitErr):
+			continue
+		default:
+			return "", fmt.Errorf("git cat-file: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// Resurrect recovers a purged issue from git history: it finds the last
+// commit in which the issue's issue.json existed and restores the issue
+// and its history from there. id may be any unique prefix of a purged
+// ID in the completion log. A history written by an older version gets
+// the snapshots it lacks recorded, as by `work migrate`, and a "restore"
+// event carrying a snapshot of the recovered issue is appended, so that
+// replay starts from there if nothing earlier can be replayed. Returns
+// the issue and the commit it was recovered from.
+func (t *Tracker) Resurrect(id, user string) (model.Issue, string, error) {
+	if t.Root == "" {
+		return model.Issue{}, "", fmt.Errorf("tracker has no root directory")
+	}
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return model.Issue{}, "", err
+	}
+	defer unlock()
+
+	id, entry, err := t.resolvePurged(id)
+	if err != nil {
+		return model.Issue{}, "", err
+	}
+	if entry != nil && entry.Archive != "" {
+		return model.Issue{}, "", fmt.Errorf("issue %s is archived; use 'work restore %s' instead", id, id)
+	}
+	if _, err := t.Store.ReadIssue(id); err == nil {
+		return model.Issue{}, "", fmt.Errorf("issue %s already exists", id)
+	}
+
+	dir := ".work/issues/" + id + "/"
+	commit, err := t.lastCommitWith(dir + "issue.json")
+	if err != nil {
+		return model.Issue{}, "", err
+	}
+	if commit == "" {
+		return model.Issue{}, "", fmt.Errorf("issue %s was never committed to git", id)
+	}
+	data, err := t.git("cat-file", "blob", commit+":./"+dir+"issue.json")
+	if err != nil {
+		return model.Issue{}, "", err
+	}
+	var issue model.Issue
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return model.Issue{}, "", fmt.Errorf("parsing issue %s at %s: %w", id, commit[:7], err)
+	}
+	// Histories predating history.jsonl are simply absent.
+	history, _ := t.git("cat-file", "blob", commit+":./"+dir+"history.jsonl")
+
+	issue.ID = id
+	logEntry := newLogEntry(issue)
+	if entry != nil {
+		logEntry.Closed = entry.Closed
+	}
+
+	// Write the recovered files as found, then save once more so the
+	// document is rewritten in the current format with a new revision.
+	if err := t.Store.WriteIssue(id, data); err != nil {
+		return model.Issue{}, "", fmt.Errorf("writing issue: %w", err)
+	}
+	if len(history) > 0 {
+		if err := t.Store.WriteEvents(id, history); err != nil {
+			return model.Issue{}, "", fmt.Errorf("writing history: %w", err)
+		}
+		if _, err := t.backfillHistory(issue, false); err != nil {
+			return model.Issue{}, "", err
+		}
+	}
+	now := time.Now().UTC()
+	issue.Updated = now
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, "", err
+	}
+	snapshot := issue
+	snapshot.Revision = 0
+	ev := model.Event{Timestamp: now, Op: "restore", By: user, Issue: &snapshot}
+	if err := t.AppendEvent(id, ev); err != nil {
+		return model.Issue{}, "", err
+	}
+	if err := t.setLogEntries([]LogEntry{logEntry}); err != nil {
+		return model.Issue{}, "", fmt.Errorf("updating log: %w", err)
+	}
+	return issue, commit, nil
+}
+
+// resolvePurged resolves a prefix against the IDs in the completion log.
+// An ID missing from the log is accepted as-is if it is a full ID of the
+// configured scheme or a legacy hex one, since its entry may have been
+// lost; the returned entry is then nil.
+func (t *Tracker) resolvePurged(prefix string) (string, *LogEntry, error) {
+	entries, err := t.LoadLog()
+	if err != nil {
+		return "", nil, err
+	}
+	byID := make(map[string]LogEntry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	tree := NewPrefixTree(slices.Collect(maps.Keys(byID)))
+	matches := tree.Resolve(prefix)
+	switch len(matches) {
+	case 0:
+		// With nothing in the log to go by, the argument itself names
+		// the paths read from git, so it must be a whole ID.
+		if strings.ContainsAny(prefix, `/\`) || strings.Contains(prefix, "..") {
+			return "", nil, fmt.Errorf("invalid issue id %q", prefix)
+		}
+		if !MatchesIDScheme(t.Config, prefix) && !IsHexID(prefix) {
+			return "", nil, fmt.Errorf("no purged issue found with prefix %q, and it is not a full issue id", prefix)
+		}
+		return prefix, nil, nil
+	case 1:
+		e := byID[matches[0]]
+		return matches[0], &e, nil
+	default:
+		return "", nil, ambiguousPrefix(prefix, tree, matches, func(id string) string {
+			return byID[id].Status + "  " + byID[id].Title
+		})
+	}
+}
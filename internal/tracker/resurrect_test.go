@@ -0,0 +1,197 @@
+package tracker
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestResurrect(t *testing.T) {
+	repo := gitRepo(t)
+	// The tracker lives below the repository root.
+	dir := filepath.Join(repo, "project")
+	tr, err := Init(dir)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Gone", "details", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.AddComment(issue.ID, "a note", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	issue, err = tr.SetStatus(issue.ID, "done", "testuser")
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-qm", "add issue")
+	if err := tr.PurgeIssue(issue); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-qm", "gc")
+
+	restored, commit, err := tr.Resurrect(issue.ID[:3], "testuser")
+	if err != nil {
+		t.Fatalf("resurrect: %v", err)
+	}
+	if commit == "" {
+		t.Error("no commit reported")
+	}
+	if restored.ID != issue.ID || restored.Description != "details" || len(restored.Comments) != 1 {
+		t.Errorf("restored = %+v", restored)
+	}
+	events, err := tr.LoadEvents(issue.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 4 || events[3].Op != "restore" || events[3].Issue == nil {
+		t.Fatalf("events = %+v, want create, comment, status, restore with snapshot", events)
+	}
+	replayed, err := tr.ReplayIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if mismatches, err := ReplayMismatches(restored, replayed); err != nil || len(mismatches) != 0 {
+		t.Errorf("replay after resurrect: %v, %v", mismatches, err)
+	}
+
+	if _, _, err := tr.Resurrect(issue.ID, "testuser"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second resurrect: got %v", err)
+	}
+	if _, _, err := tr.Resurrect("zzzzzz", "testuser"); err == nil || !strings.Contains(err.Error(), "never committed") {
+		t.Errorf("unknown id: got %v", err)
+	}
+	for _, arg := range []string{"zz", "../../etc", issue.ID + "/../x", `a\b`} {
+		if _, _, err := tr.Resurrect(arg, "testuser"); err == nil || strings.Contains(err.Error(), "never committed") {
+			t.Errorf("resurrect %q: got %v, want it rejected before reading git", arg, err)
+		}
+	}
+}
+
+func TestResurrect_WithoutHistory(t *testing.T) {
+	repo := gitRepo(t)
+	tr, err := Init(repo)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	issue, err := tr.CreateIssue("Old", "", "", 2, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// Trackers from before history.jsonl existed.
+	if err := os.Remove(filepath.Join(repo, ".work", "issues", issue.ID, "history.jsonl")); err != nil {
+		t.Fatalf("remove history: %v", err)
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-qm", "add issue")
+	if err := tr.Store.RemoveIssue(issue.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	restored, _, err := tr.Resurrect(issue.ID, "testuser")
+	if err != nil {
+		t.Fatalf("resurrect: %v", err)
+	}
+	replayed, err := tr.ReplayIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if mismatches, err := ReplayMismatches(restored, replayed); err != nil || len(mismatches) != 0 {
+		t.Errorf("replay after resurrect: %v, %v", mismatches, err)
+	}
+}
+
+func TestResurrect_LegacyHistory(t *testing.T) {
+	repo := gitRepo(t)
+	tr, err := Init(repo)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	// Older versions wrote create events without the initial fields, and
+	// edits without their values.
+	histories := map[string]string{
+		"Closed": `{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}
+{"ts":"2024-01-02T00:00:00Z","op":"status","from":"open","to":"done","by":"old"}
+`,
+		"Renamed": `{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}
+{"ts":"2024-01-02T00:00:00Z","op":"edit","fields":["title"],"by":"old"}
+{"ts":"2024-01-03T00:00:00Z","op":"status","from":"open","to":"done","by":"old"}
+`,
+	}
+	ids := make(map[string]string)
+	for title, history := range histories {
+		issue, err := tr.CreateIssue(title, "", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		issue.Status = "done"
+		if err := tr.SaveIssue(issue); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		path := filepath.Join(repo, ".work", "issues", issue.ID, "history.jsonl")
+		if err := os.WriteFile(path, []byte(history), 0o644); err != nil {
+			t.Fatalf("write history: %v", err)
+		}
+		ids[title] = issue.ID
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-qm", "add issues")
+	for _, id := range ids {
+		if err := tr.Store.RemoveIssue(id); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if _, _, err := tr.Resurrect(id, "testuser"); err != nil {
+			t.Fatalf("resurrect: %v", err)
+		}
+		if _, err := tr.ReplayIssue(id); err != nil {
+			t.Errorf("replay %s: %v", id, err)
+		}
+	}
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at, err := tr.IssueAt(ids["Closed"], created)
+	if err != nil {
+		t.Fatalf("issue at: %v", err)
+	}
+	if at.Status != "open" || at.Title != "Closed" {
+		t.Errorf("at creation: status %q title %q", at.Status, at.Title)
+	}
+	// The title before the edit is unknown, so history starts there.
+	if _, err := tr.IssueAt(ids["Renamed"], created); !errors.Is(err, ErrIncompleteHistory) {
+		t.Errorf("before legacy edit: got %v, want ErrIncompleteHistory", err)
+	}
+	at, err = tr.IssueAt(ids["Renamed"], time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil || at.Status != "open" {
+		t.Errorf("after legacy edit: got %+v, %v", at, err)
+	}
+}
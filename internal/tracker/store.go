@@ -0,0 +1,449 @@
+package tracker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store persists issue documents, per-issue event streams and the
+// completion log. Documents and streams are opaque bytes; encoding is the
+// tracker's concern. Reads of a missing issue return an error wrapping
+// fs.ErrNotExist, while a missing event stream or log reads as empty.
+type Store interface {
+	// ListIssueIDs returns the IDs of all stored issues in sorted order.
+	ListIssueIDs() ([]string, error)
+	ReadIssue(id string) ([]byte, error)
+	WriteIssue(id string, data []byte) error
+	RemoveIssue(id string) error
+	RenameIssue(oldID, newID string) error
+
+	OpenEvents(id string) (io.ReadCloser, error)
+	AppendEvent(id string, line []byte) error
+	WriteEvents(id string, data []byte) error
+
+	OpenLog() (io.ReadCloser, error)
+	AppendLog(line []byte) error
+	WriteLog(data []byte) error
+
+	// TryLock attempts to take the named advisory lock without blocking.
+	// Shared holders may coexist; an exclusive holder excludes everyone.
+	// Returns ok=false when the lock is currently held elsewhere.
+	TryLock(name string, exclusive bool) (unlock func(), ok bool, err error)
+}
+
+// FSStore is the on-disk Store backing a .work/ directory:
+//
+//	<dir>/issues/<id>/issue.json
+//	<dir>/issues/<id>/history.jsonl
+//	<dir>/log.jsonl
+//	<dir>/locks/<name>.lock
+//	<dir>/cache/index.json
+//	<dir>/tmp/<encoded path>.tmp-<random>
+//	<dir>/archive/<yyyy-mm>.tar.gz
+//
+// Whole-file writes are atomic; see writeFileAtomic and Recover. Locks
+// use flock(2), so they are released automatically if the holder dies.
+type FSStore struct {
+	Dir string
+}
+
+// NewFSStore returns a Store rooted at root/.work.
+func NewFSStore(root string) *FSStore {
+	return &FSStore{Dir: filepath.Join(root, ".work")}
+}
+
+func (s *FSStore) issuesDir() string         { return filepath.Join(s.Dir, "issues") }
+func (s *FSStore) issueDir(id string) string { return filepath.Join(s.Dir, "issues", id) }
+func (s *FSStore) logPath() string           { return filepath.Join(s.Dir, "log.jsonl") }
+
+func (s *FSStore) historyPath(id string) string {
+	return filepath.Join(s.issueDir(id), "history.jsonl")
+}
+
+func (s *FSStore) ListIssueIDs() ([]string, error) {
+	entries, err := os.ReadDir(s.issuesDir())
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	return ids, nil
+}
+
+func (s *FSStore) ReadIssue(id string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.issueDir(id), "issue.json"))
+}
+
+func (s *FSStore) WriteIssue(id string, data []byte) error {
+	dir := s.issueDir(id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating issue dir: %w", err)
+	}
+	return writeFileAtomic(s.Dir, filepath.Join(dir, "issue.json"), data, 0o644)
+}
+
+func (s *FSStore) RemoveIssue(id string) error {
+	return os.RemoveAll(s.issueDir(id))
+}
+
+func (s *FSStore) RenameIssue(oldID, newID string) error {
+	if _, err := os.Stat(s.issueDir(newID)); err == nil {
+		return fmt.Errorf("issue %s already exists", newID)
+	}
+	return os.Rename(s.issueDir(oldID), s.issueDir(newID))
+}
+
+func (s *FSStore) OpenEvents(id string) (io.ReadCloser, error) {
+	return openOrEmpty(s.historyPath(id))
+}
+
+func (s *FSStore) AppendEvent(id string, line []byte) error {
+	return appendLine(s.historyPath(id), line)
+}
+
+func (s *FSStore) WriteEvents(id string, data []byte) error {
+	return writeFileAtomic(s.Dir, s.historyPath(id), data, 0o644)
+}
+
+func (s *FSStore) OpenLog() (io.ReadCloser, error) {
+	return openOrEmpty(s.logPath())
+}
+
+func (s *FSStore) AppendLog(line []byte) error {
+	return appendLine(s.logPath(), line)
+}
+
+func (s *FSStore) WriteLog(data []byte) error {
+	return writeFileAtomic(s.Dir, s.logPath(), data, 0o644)
+}
+
+func (s *FSStore) TryLock(name string, exclusive bool) (func(), bool, error) {
+	dir := filepath.Join(s.Dir, "locks")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, false, err
+	}
+	// Lock files are runtime state; keep them out of git.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0o644)
+	}
+	return tryFlock(filepath.Join(dir, name+".lock"), exclusive)
+}
+
+// IssueStamps stats every issue.json. A directory without one gets a
+// zero stamp, so that loading it reports the problem.
+func (s *FSStore) IssueStamps() (map[string]IssueStamp, error) {
+	ids, err := s.ListIssueIDs()
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]IssueStamp, len(ids))
+	for _, id := range ids {
+		info, err := os.Stat(filepath.Join(s.issueDir(id), "issue.json"))
+		if err != nil {
+			stamps[id] = IssueStamp{}
+			continue
+		}
+		stamps[id] = IssueStamp{ModTime: info.ModTime(), Size: info.Size()}
+	}
+	return stamps, nil
+}
+
+func (s *FSStore) ReadIndex() ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Dir, "cache", "index.json"))
+}
+
+func (s *FSStore) WriteIndex(data []byte) error {
+	dir := filepath.Join(s.Dir, "cache")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// The index is derived from the issues; keep it out of git.
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0o644)
+	}
+	return writeFileAtomic(s.Dir, filepath.Join(dir, "index.json"), data, 0o644)
+}
+
+func (s *FSStore) archivePath(archive string) string {
+	return filepath.Join(s.Dir, "archive", archive+".tar.gz")
+}
+
+// ArchiveIssues moves each issue directory into the archive, replacing
+// any earlier copy of the same issue there. The archive is rewritten
+// atomically before the directories are removed, so an interruption
+// leaves the issue in both places rather than in neither.
+func (s *FSStore) ArchiveIssues(archive string, ids []string) error {
+	path := s.archivePath(archive)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	files, err := readArchive(path)
+	if err != nil {
+		return err
+	}
+	moving := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		moving[id] = true
+	}
+	files = slices.DeleteFunc(files, func(f archiveFile) bool { return moving[f.id] })
+	for _, id := range ids {
+		entries, err := os.ReadDir(s.issueDir(id))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(s.issueDir(id), e.Name()))
+			if err != nil {
+				return err
+			}
+			info, err := e.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, archiveFile{id: id, name: e.Name(), modTime: info.ModTime(), data: data})
+		}
+	}
+	if err := writeArchive(s.Dir, path, files); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := os.RemoveAll(s.issueDir(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArchivedIssues maps the ID of every archived issue to its archive.
+func (s *FSStore) ArchivedIssues() (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "archive", "*.tar.gz"))
+	if err != nil {
+		return nil, err
+	}
+	archived := make(map[string]string)
+	for _, path := range paths {
+		files, err := readArchive(path)
+		if err != nil {
+			return nil, err
+		}
+		archive := strings.TrimSuffix(filepath.Base(path), ".tar.gz")
+		for _, f := range files {
+			archived[f.id] = archive
+		}
+	}
+	return archived, nil
+}
+
+// UnarchiveIssue moves an issue out of the archive and back into
+// issues/. The archive file is removed once it holds no more issues.
+func (s *FSStore) UnarchiveIssue(archive, id string) error {
+	if _, err := os.Stat(s.issueDir(id)); err == nil {
+		return fmt.Errorf("issue %s already exists", id)
+	}
+	path := s.archivePath(archive)
+	files, err := readArchive(path)
+	if err != nil {
+		return err
+	}
+	var rest []archiveFile
+	found := false
+	for _, f := range files {
+		if f.id != id {
+			rest = append(rest, f)
+			continue
+		}
+		found = true
+		if err := os.MkdirAll(s.issueDir(id), 0o755); err != nil {
+			return fmt.Errorf("creating issue dir: %w", err)
+		}
+		if err := writeFileAtomic(s.Dir, filepath.Join(s.issueDir(id), f.name), f.data, 0o644); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("issue %s is not in archive %s: %w", id, archive, fs.ErrNotExist)
+	}
+	if len(rest) == 0 {
+		return os.Remove(path)
+	}
+	return writeArchive(s.Dir, path, rest)
+}
+
+func openOrEmpty(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// MemoryStore is a Store held entirely in memory. It is safe for
+// concurrent use and intended for tests and embedders that don't want
+// a .work/ tree.
+type MemoryStore struct {
+	mu     sync.Mutex
+	issues map[string][]byte
+	events map[string][]byte
+	log    []byte
+	locks  map[string]*sync.RWMutex
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		issues: make(map[string][]byte),
+		events: make(map[string][]byte),
+		locks:  make(map[string]*sync.RWMutex),
+	}
+}
+
+func (s *MemoryStore) ListIssueIDs() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.issues))
+	for id := range s.issues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *MemoryStore) ReadIssue(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.issues[id]
+	if !ok {
+		return nil, fmt.Errorf("issue %s: %w", id, fs.ErrNotExist)
+	}
+	return bytes.Clone(data), nil
+}
+
+func (s *MemoryStore) WriteIssue(id string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issues[id] = bytes.Clone(data)
+	return nil
+}
+
+func (s *MemoryStore) RemoveIssue(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.issues, id)
+	delete(s.events, id)
+	return nil
+}
+
+func (s *MemoryStore) RenameIssue(oldID, newID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.issues[oldID]
+	if !ok {
+		return fmt.Errorf("issue %s: %w", oldID, fs.ErrNotExist)
+	}
+	if _, exists := s.issues[newID]; exists {
+		return fmt.Errorf("issue %s already exists", newID)
+	}
+	s.issues[newID] = data
+	delete(s.issues, oldID)
+	if ev, ok := s.events[oldID]; ok {
+		s.events[newID] = ev
+		delete(s.events, oldID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) OpenEvents(id string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(bytes.Clone(s.events[id]))), nil
+}
+
+func (s *MemoryStore) AppendEvent(id string, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.issues[id]; !ok {
+		return fmt.Errorf("issue %s: %w", id, fs.ErrNotExist)
+	}
+	s.events[id] = append(append(s.events[id], line...), '\n')
+	return nil
+}
+
+func (s *MemoryStore) WriteEvents(id string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.issues[id]; !ok {
+		return fmt.Errorf("issue %s: %w", id, fs.ErrNotExist)
+	}
+	s.events[id] = bytes.Clone(data)
+	return nil
+}
+
+func (s *MemoryStore) OpenLog() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(bytes.Clone(s.log))), nil
+}
+
+func (s *MemoryStore) AppendLog(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = append(append(s.log, line...), '\n')
+	return nil
+}
+
+func (s *MemoryStore) WriteLog(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = bytes.Clone(data)
+	return nil
+}
+
+func (s *MemoryStore) TryLock(name string, exclusive bool) (func(), bool, error) {
+	s.mu.Lock()
+	l, ok := s.locks[name]
+	if !ok {
+		l = &sync.RWMutex{}
+		s.locks[name] = l
+	}
+	s.mu.Unlock()
+	if exclusive {
+		if !l.TryLock() {
+			return nil, false, nil
+		}
+		return l.Unlock, true, nil
+	}
+	if !l.TryRLock() {
+		return nil, false, nil
+	}
+	return l.RUnlock, true, nil
+}
@@ -0,0 +1,187 @@
+package tracker
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".work", "issues"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return map[string]Store{
+		"fs":     NewFSStore(root),
+		"memory": NewMemoryStore(),
+	}
+}
+
+func readAllString(t *testing.T, open func() (io.ReadCloser, error)) string {
+	t.Helper()
+	rc, err := open()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
+
+func TestStore_IssueRoundTrip(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.ReadIssue("missing"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("read missing: got %v, want fs.ErrNotExist", err)
+			}
+			for _, id := range []string{"bbb", "aaa"} {
+				if err := s.WriteIssue(id, []byte(id+"\n")); err != nil {
+					t.Fatalf("write %s: %v", id, err)
+				}
+			}
+			ids, err := s.ListIssueIDs()
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if len(ids) != 2 || ids[0] != "aaa" || ids[1] != "bbb" {
+				t.Errorf("ids: got %v, want [aaa bbb]", ids)
+			}
+			data, err := s.ReadIssue("aaa")
+			if err != nil || string(data) != "aaa\n" {
+				t.Errorf("read aaa: got %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestStore_EventsAndRename(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if got := readAllString(t, func() (io.ReadCloser, error) { return s.OpenEvents("old") }); got != "" {
+				t.Errorf("missing events should read empty, got %q", got)
+			}
+			if err := s.WriteIssue("old", []byte("{}\n")); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := s.AppendEvent("old", []byte(`{"op":"create"}`)); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			if err := s.AppendEvent("old", []byte(`{"op":"status"}`)); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			if err := s.RenameIssue("old", "new"); err != nil {
+				t.Fatalf("rename: %v", err)
+			}
+			if _, err := s.ReadIssue("old"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("old id should be gone, got %v", err)
+			}
+			want := "{\"op\":\"create\"}\n{\"op\":\"status\"}\n"
+			if got := readAllString(t, func() (io.ReadCloser, error) { return s.OpenEvents("new") }); got != want {
+				t.Errorf("events: got %q, want %q", got, want)
+			}
+			if err := s.WriteEvents("new", []byte("{\"op\":\"create\"}\n")); err != nil {
+				t.Fatalf("write events: %v", err)
+			}
+			if got := readAllString(t, func() (io.ReadCloser, error) { return s.OpenEvents("new") }); got != "{\"op\":\"create\"}\n" {
+				t.Errorf("rewritten events: got %q", got)
+			}
+			if err := s.RemoveIssue("new"); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+			ids, _ := s.ListIssueIDs()
+			if len(ids) != 0 {
+				t.Errorf("ids after remove: got %v", ids)
+			}
+		})
+	}
+}
+
+func TestStore_Log(t *testing.T) {
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if got := readAllString(t, s.OpenLog); got != "" {
+				t.Errorf("missing log should read empty, got %q", got)
+			}
+			_ = s.AppendLog([]byte("a"))
+			_ = s.AppendLog([]byte("b"))
+			if got := readAllString(t, s.OpenLog); got != "a\nb\n" {
+				t.Errorf("log: got %q", got)
+			}
+			if err := s.WriteLog([]byte("c\n")); err != nil {
+				t.Fatalf("write log: %v", err)
+			}
+			if got := readAllString(t, s.OpenLog); got != "c\n" {
+				t.Errorf("rewritten log: got %q", got)
+			}
+		})
+	}
+}
+
+func TestMemoryTracker_Workflow(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+
+	parent, err := tr.CreateIssue("Parent", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := tr.CreateIssue("Child", "", "", 2, nil, "", parent.ID, "testuser")
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := tr.SetStatus(child.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := tr.AddComment(child.ID, "shipped", "testuser"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if err := tr.CompactIssue(child.ID); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+
+	events, err := tr.LoadEvents(child.ID)
+	if err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(events) != 2 || events[0].Op != "create" || events[1].To != "done" {
+		t.Errorf("compacted events: got %+v", events)
+	}
+
+	newID, err := tr.RehashIssue(parent.ID)
+	if err != nil {
+		t.Fatalf("rehash: %v", err)
+	}
+	reloaded, err := tr.LoadIssue(child.ID)
+	if err != nil {
+		t.Fatalf("load child: %v", err)
+	}
+	if reloaded.ParentID != newID {
+		t.Errorf("child parent: got %q, want %q", reloaded.ParentID, newID)
+	}
+
+	if err := tr.PurgeIssue(reloaded); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	issues, err := tr.ListIssues()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != newID {
+		t.Errorf("issues after purge: got %+v", issues)
+	}
+	entries, err := tr.LoadLog()
+	if err != nil {
+		t.Fatalf("load log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != child.ID {
+		t.Errorf("log entries: got %+v", entries)
+	}
+}
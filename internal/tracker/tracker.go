@@ -2,14 +2,17 @@
 package tracker
 
 import (
-	"bufio"
-	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jfmyers9/work/internal/model"
@@ -107,51 +110,54 @@ func SortIssues(issues []model.Issue, sortBy string) {
 type Tracker struct {
 	Root   string
 	Config model.Config
+	Store  Store
+
+	// LockTimeout bounds how long mutations wait for another process to
+	// release a lock. Zero means DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	// Workers bounds how many issues Issues and AllEvents read at once.
+	// Zero means GOMAXPROCS.
+	Workers int
+
+	// Recovered describes interrupted writes cleaned up by Load.
+	Recovered []string
+
+	// Strict makes LoadEvents and LoadLog fail on the first corrupt line
+	// instead of skipping it and recording a warning; see Warnings.
+	Strict bool
+
+	// IgnoreWIPLimits lets SetStatus move issues into states that are at
+	// their WIP limit.
+	IgnoreWIPLimits bool
+
+	// HookOutput receives what hooks in .work/hooks/ print, and warnings
+	// about failed post-hooks and automation rules. Nil discards them.
+	HookOutput io.Writer
+
+	// NoAutomation turns off the automation rules in Config.Automation.
+	NoAutomation bool
+
+	warnMu   sync.Mutex
+	warnings []LineWarning
+}
+
+// New returns a tracker backed by the given store. Root is left empty;
+// use Init or Load for a tracker rooted in a .work/ directory.
+func New(cfg model.Config, store Store) *Tracker {
+	return &Tracker{Config: cfg, Store: store}
 }
 
 // MinPrefix returns the shortest prefix of id that uniquely identifies it
-// among allIDs. The returned prefix is at least 3 characters long.
+// among allIDs. Callers computing prefixes for many IDs should build a
+// PrefixTree once instead.
 func MinPrefix(id string, allIDs []string) string {
-	for length := 1; length < len(id); length++ {
-		prefix := id[:length]
-		unique := true
-		for _, other := range allIDs {
-			if other != id && strings.HasPrefix(other, prefix) {
-				unique = false
-				break
-			}
-		}
-		if unique {
-			return prefix
-		}
-	}
-	return id
+	return NewPrefixTree(allIDs).Shortest(id)
 }
 
 // MinPrefixes returns the minimum unique prefix for each ID in the slice.
 func MinPrefixes(ids []string) map[string]string {
-	result := make(map[string]string, len(ids))
-	for _, id := range ids {
-		result[id] = MinPrefix(id, ids)
-	}
-	return result
-}
-
-// crockfordAlphabet is Crockford's Base32 alphabet (lowercase).
-// Excludes i, l, o, u to avoid visual ambiguity.
-const crockfordAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
-
-// GenerateID produces a random Crockford's Base32 string of the configured length.
-func (t *Tracker) GenerateID() (string, error) {
-	buf := make([]byte, t.Config.IDLength)
-	if _, err := rand.Read(buf); err != nil {
-		return "", fmt.Errorf("generating id: %w", err)
-	}
-	out := make([]byte, t.Config.IDLength)
-	for i, b := range buf {
-		out[i] = crockfordAlphabet[int(b)%len(crockfordAlphabet)]
-	}
-	return string(out), nil
+	return NewPrefixTree(ids).ShortestAll()
 }
 
 // Init creates the .work directory structure and writes the default config.
@@ -173,12 +179,13 @@ func Init(root string) (*Tracker, error) {
 		}
 	} else {
 		cfg = model.DefaultConfig()
+		cfg.SchemaVersion = SchemaVersion
 		data, err := json.Marshal(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("marshaling config: %w", err)
 		}
 		data = append(data, '\n')
-		if err := os.WriteFile(cfgPath, data, 0o644); err != nil {
+		if err := writeFileAtomic(filepath.Join(root, ".work"), cfgPath, data, 0o644); err != nil {
 			return nil, fmt.Errorf("writing config: %w", err)
 		}
 	}
@@ -187,13 +194,16 @@ func Init(root string) (*Tracker, error) {
 		return nil, fmt.Errorf("writing .gitattributes: %w", err)
 	}
 
-	return &Tracker{Root: root, Config: cfg}, nil
+	return &Tracker{Root: root, Config: cfg, Store: NewFSStore(root)}, nil
 }
 
 var gitattributesLines = []string{
 	".work/issues/** linguist-generated diff=work",
 	".work/log.jsonl linguist-generated diff=work",
 	".work/config.json linguist-generated diff=work",
+	".work/issues/*/issue.json merge=work",
+	".work/issues/*/history.jsonl merge=work",
+	".work/log.jsonl merge=work",
 }
 
 func writeGitattributes(root string) error {
@@ -234,8 +244,15 @@ func writeGitattributes(root string) error {
 	return nil
 }
 
-// Load reads an existing tracker from disk.
+// Load reads an existing tracker from disk, first recovering from any
+// interrupted atomic writes left in .work/. It returns an error wrapping
+// ErrNewerSchema if the tree was written by a newer version of work.
 func Load(root string) (*Tracker, error) {
+	store := NewFSStore(root)
+	recovered, err := store.Recover()
+	if err != nil {
+		return nil, fmt.Errorf("recovering interrupted writes: %w", err)
+	}
 	cfgPath := filepath.Join(root, ".work", "config.json")
 	data, err := os.ReadFile(cfgPath)
 	if err != nil {
@@ -245,27 +262,66 @@ func Load(root string) (*Tracker, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
-	return &Tracker{Root: root, Config: cfg}, nil
+	if err := checkSchema(cfg.SchemaVersion); err != nil {
+		return nil, err
+	}
+	return &Tracker{Root: root, Config: cfg, Store: store, Recovered: recovered}, nil
+}
+
+// ConflictError reports a stale write: the issue was saved by someone
+// else after the caller loaded it. Current holds the stored version.
+type ConflictError struct {
+	ID       string
+	Expected int
+	Current  model.Issue
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("issue %s was modified by someone else (revision %d, you have %d)", e.ID, e.Current.Revision, e.Expected)
 }
 
-// SaveIssue writes an issue to its directory.
+// SaveIssue writes an issue to the store. issue.Revision is the revision
+// the caller loaded; if the stored issue has moved on since, the write is
+// rejected with a *ConflictError. On success the stored revision is
+// incremented. Callers should hold LockIssue so the check and the write
+// happen atomically.
 func (t *Tracker) SaveIssue(issue model.Issue) error {
-	dir := filepath.Join(t.Root, ".work", "issues", issue.ID)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("creating issue dir: %w", err)
+	return t.saveIssue(&issue)
+}
+
+// saveIssue is SaveIssue, updating issue.Revision in place on success.
+func (t *Tracker) saveIssue(issue *model.Issue) error {
+	current, err := t.LoadIssue(issue.ID)
+	switch {
+	case err == nil:
+		if current.Revision != issue.Revision {
+			return &ConflictError{ID: issue.ID, Expected: issue.Revision, Current: current}
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if issue.Revision != 0 {
+			return fmt.Errorf("issue %s no longer exists: %w", issue.ID, err)
+		}
+	default:
+		return err
 	}
-	data, err := json.Marshal(issue)
+
+	saved := *issue
+	saved.Revision++
+	data, err := json.Marshal(saved)
 	if err != nil {
 		return fmt.Errorf("marshaling issue: %w", err)
 	}
 	data = append(data, '\n')
-	return os.WriteFile(filepath.Join(dir, "issue.json"), data, 0o644)
+	if err := t.Store.WriteIssue(issue.ID, data); err != nil {
+		return err
+	}
+	issue.Revision = saved.Revision
+	return nil
 }
 
-// LoadIssue reads an issue from its directory.
+// LoadIssue reads an issue from the store.
 func (t *Tracker) LoadIssue(id string) (model.Issue, error) {
-	path := filepath.Join(t.Root, ".work", "issues", id, "issue.json")
-	data, err := os.ReadFile(path)
+	data, err := t.Store.ReadIssue(id)
 	if err != nil {
 		return model.Issue{}, fmt.Errorf("reading issue: %w", err)
 	}
@@ -278,17 +334,11 @@ func (t *Tracker) LoadIssue(id string) (model.Issue, error) {
 
 // AppendEvent writes an event as a JSON line to the issue's history.jsonl.
 func (t *Tracker) AppendEvent(id string, event model.Event) error {
-	path := filepath.Join(t.Root, ".work", "issues", id, "history.jsonl")
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return fmt.Errorf("opening history: %w", err)
-	}
-	defer func() { _ = f.Close() }()
 	data, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("marshaling event: %w", err)
 	}
-	if _, err := fmt.Fprintf(f, "%s\n", data); err != nil {
+	if err := t.Store.AppendEvent(id, data); err != nil {
 		return fmt.Errorf("writing event: %w", err)
 	}
 	return nil
@@ -304,29 +354,43 @@ func ValidateType(cfg model.Config, issueType string) error {
 	return fmt.Errorf("invalid type %q (allowed: %s)", issueType, strings.Join(cfg.Types, ", "))
 }
 
-// CreateIssue generates an ID, saves the issue, and records a creation event.
+// CreateIssue generates an unused ID in the configured scheme, saves the
+// issue, and records a creation event.
 func (t *Tracker) CreateIssue(title, description, assignee string, priority int, labels []string, issueType, parentID, user string) (model.Issue, error) {
+	issue, event, err := t.createIssue(title, description, assignee, priority, labels, issueType, parentID, user)
+	if err != nil {
+		return model.Issue{}, err
+	}
+	t.postHook(hookInput{Hook: HookPostCreate, User: user, Issue: &issue, Event: &event})
+	return t.automateChange(issue, []model.Event{event}, user)
+}
+
+func (t *Tracker) createIssue(title, description, assignee string, priority int, labels []string, issueType, parentID, user string) (model.Issue, model.Event, error) {
 	if issueType == "" {
 		issueType = t.Config.DefaultType
 	}
 	if err := ValidateType(t.Config, issueType); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
 	if parentID != "" {
 		if err := t.validateParent(parentID); err != nil {
-			return model.Issue{}, err
+			return model.Issue{}, model.Event{}, err
 		}
 	}
-	id, err := t.GenerateID()
+	next, err := t.idGenerator()
 	if err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
+	}
+	id, unlock, err := t.claimID(next, false)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 	now := time.Now().UTC()
 	issue := model.Issue{
 		ID:          id,
 		Title:       title,
 		Description: description,
-		Status:      t.Config.DefaultState,
+		Status:      t.Config.Workflow(issueType).DefaultState,
 		Type:        issueType,
 		Priority:    priority,
 		Labels:      labels,
@@ -335,60 +399,96 @@ func (t *Tracker) CreateIssue(title, description, assignee string, priority int,
 		Created:     now,
 		Updated:     now,
 	}
-	if err := t.SaveIssue(issue); err != nil {
-		return model.Issue{}, err
-	}
+	initial := issue
 	event := model.Event{
 		Timestamp: now,
 		Op:        "create",
 		By:        user,
+		Issue:     &initial,
+	}
+	if t.hookPath(HookPreCreate) != "" {
+		// The hook runs without the ID's lock, which shares the
+		// tracker-wide one; the ID is claimed again afterwards.
+		unlock()
+		if err := t.runHook(hookInput{Hook: HookPreCreate, User: user, Issue: &issue, Event: &event}); err != nil {
+			return model.Issue{}, model.Event{}, err
+		}
+		claimed := false
+		_, unlock, err = t.claimID(func() (string, error) {
+			if claimed {
+				return "", fmt.Errorf("id %s was taken while the %s hook ran; try again", id, HookPreCreate)
+			}
+			claimed = true
+			return id, nil
+		}, false)
+		if err != nil {
+			return model.Issue{}, model.Event{}, err
+		}
+	}
+	defer unlock()
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 	if err := t.AppendEvent(id, event); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
-	return issue, nil
+	return issue, event, nil
 }
 
+// maxAmbiguousShown caps how many candidates an ambiguous-prefix error
+// lists, since each one is loaded to show its title.
+const maxAmbiguousShown = 10
+
 // ResolvePrefix finds an issue ID matching the given prefix.
 // Returns an error if zero or multiple issues match.
 func (t *Tracker) ResolvePrefix(prefix string) (string, error) {
-	issuesDir := filepath.Join(t.Root, ".work", "issues")
-	entries, err := os.ReadDir(issuesDir)
+	ids, err := t.Store.ListIssueIDs()
 	if err != nil {
 		return "", fmt.Errorf("reading issues dir: %w", err)
 	}
-	var matches []string
-	for _, e := range entries {
-		if e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
-			matches = append(matches, e.Name())
-		}
-	}
+	tree := NewPrefixTree(ids)
+	matches := tree.Resolve(prefix)
 	switch len(matches) {
 	case 0:
 		return "", fmt.Errorf("no issue found with prefix %q", prefix)
 	case 1:
 		return matches[0], nil
 	default:
-		short := MinPrefixes(matches)
-		var lines []string
-		for _, m := range matches {
-			issue, err := t.LoadIssue(m)
+		return "", ambiguousPrefix(prefix, tree, matches, func(id string) string {
+			issue, err := t.LoadIssue(id)
 			if err != nil {
-				lines = append(lines, fmt.Sprintf("  %s", short[m]))
-			} else {
-				lines = append(lines, fmt.Sprintf("  %s  %s  %s", short[m], issue.Status, issue.Title))
+				return ""
 			}
+			return issue.Status + "  " + issue.Title
+		})
+	}
+}
+
+// ambiguousPrefix lists the IDs a prefix matched, each by its shortest
+// unique prefix followed by describe(id) when that is non-empty.
+func ambiguousPrefix(prefix string, tree *PrefixTree, matches []string, describe func(id string) string) error {
+	var lines []string
+	for i, m := range matches {
+		if i == maxAmbiguousShown {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(matches)-i))
+			break
 		}
-		return "", fmt.Errorf("ambiguous prefix %q — did you mean:\n%s", prefix, strings.Join(lines, "\n"))
+		line := "  " + tree.Shortest(m)
+		if d := describe(m); d != "" {
+			line += "  " + d
+		}
+		lines = append(lines, line)
 	}
+	return fmt.Errorf("ambiguous prefix %q — did you mean:\n%s", prefix, strings.Join(lines, "\n"))
 }
 
-// ValidateTransition checks if moving from one state to another is allowed by config.
-func ValidateTransition(cfg model.Config, from, to string) error {
+// ValidateTransition checks if moving from one state to another is allowed
+// by the workflow of issueType.
+func ValidateTransition(cfg model.Config, issueType, from, to string) error {
 	if from == to {
 		return fmt.Errorf("invalid transition: already in state %q", from)
 	}
-	allowed, ok := cfg.Transitions[from]
+	allowed, ok := cfg.Workflow(issueType).Transitions[from]
 	if !ok {
 		return fmt.Errorf("invalid transition: unknown state %q", from)
 	}
@@ -400,22 +500,67 @@ func ValidateTransition(cfg model.Config, from, to string) error {
 	return fmt.Errorf("invalid transition: cannot move from %q to %q (allowed: %s)", from, to, strings.Join(allowed, ", "))
 }
 
-// SetStatus validates the transition and updates the issue's status.
+// ShortcutState returns the state a shortcut aimed at target moves issue
+// to: target itself when the issue's workflow allows it, else the first
+// allowed state in the same category. When neither exists target is
+// returned, and SetStatus reports the transition as invalid.
+func ShortcutState(cfg model.Config, issue model.Issue, target string) string {
+	allowed := cfg.Workflow(issue.Type).Transitions[issue.Status]
+	if slices.Contains(allowed, target) {
+		return target
+	}
+	category := cfg.Category(target)
+	for _, s := range allowed {
+		if cfg.Category(s) == category {
+			return s
+		}
+	}
+	return target
+}
+
+// SetStatus validates the transition, checks the guards and WIP limit on
+// the new state and updates the issue's status, running the transition
+// hooks around the change and then any automation rules it triggers.
 func (t *Tracker) SetStatus(id, newStatus, user string) (model.Issue, error) {
-	issue, err := t.LoadIssue(id)
+	issue, event, err := t.setStatus(id, newStatus, user)
 	if err != nil {
 		return model.Issue{}, err
 	}
-	if err := ValidateTransition(t.Config, issue.Status, newStatus); err != nil {
-		return model.Issue{}, err
+	t.postTransition(issue, []model.Event{event}, user)
+	return t.automateChange(issue, []model.Event{event}, user)
+}
+
+func (t *Tracker) setStatus(id, newStatus, user string) (issue model.Issue, event model.Event, err error) {
+	err = t.withPreTransition(user, func(approved *hookPlan) error {
+		issue, event, err = t.trySetStatus(id, newStatus, user, approved)
+		return err
+	})
+	return issue, event, err
+}
+
+// trySetStatus is one attempt of setStatus; see withPreTransition.
+func (t *Tracker) trySetStatus(id, newStatus, user string, approved *hookPlan) (model.Issue, model.Event, error) {
+	unlockState, held, err := t.lockState(newStatus)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	defer unlockState()
+	unlock, err := t.lockIssue(id, held)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	defer unlock()
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	if err := ValidateTransition(t.Config, issue.Type, issue.Status, newStatus); err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 	oldStatus := issue.Status
 	now := time.Now().UTC()
 	issue.Status = newStatus
 	issue.Updated = now
-	if err := t.SaveIssue(issue); err != nil {
-		return model.Issue{}, err
-	}
 	event := model.Event{
 		Timestamp: now,
 		Op:        "status",
@@ -423,10 +568,65 @@ func (t *Tracker) SetStatus(id, newStatus, user string) (model.Issue, error) {
 		To:        newStatus,
 		By:        user,
 	}
+	if err := t.beginTransition(issue, []model.Event{event}, approved); err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
 	if err := t.AppendEvent(id, event); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
+	}
+	return issue, event, nil
+}
+
+// lockState takes the tracker-wide lock if state has a WIP limit, so
+// that counting the issues already there and moving one in can't
+// interleave with another move into the same state, and reports whether
+// it did. Take it before the issue lock.
+func (t *Tracker) lockState(state string) (func(), bool, error) {
+	if _, ok := t.Config.WIPLimits[state]; !ok || t.IgnoreWIPLimits {
+		return func() {}, false, nil
+	}
+	unlock, err := t.LockTracker()
+	return unlock, err == nil, err
+}
+
+// beginTransition checks that issue, as it will be saved, may make the
+// status changes in events: the guards and WIP limit on each new state,
+// then the pre-transition hook. Every status change goes through it,
+// within withPreTransition, and through postTransition once saved.
+// Callers hold the issue lock and the lock from lockState, so unless
+// approved covers these changes a *hookPlan is returned for the hook to
+// run without them.
+func (t *Tracker) beginTransition(issue model.Issue, events []model.Event, approved *hookPlan) error {
+	var transitions []model.Event
+	for _, event := range events {
+		if event.Op != "status" {
+			continue
+		}
+		if err := t.CheckGuards(issue, event.To); err != nil {
+			return err
+		}
+		if err := t.checkWIPLimit(issue, event.To); err != nil {
+			return err
+		}
+		transitions = append(transitions, event)
+	}
+	if len(transitions) == 0 || t.hookPath(HookPreTransition) == "" || approved.covers(issue, transitions) {
+		return nil
+	}
+	return &hookPlan{issue: issue, events: transitions}
+}
+
+// postTransition runs the post-transition hook for each status change
+// in events, made by user to issue.
+func (t *Tracker) postTransition(issue model.Issue, events []model.Event, user string) {
+	for _, event := range events {
+		if event.Op == "status" {
+			t.postHook(hookInput{Hook: HookPostTransition, User: user, Issue: &issue, Event: &event})
+		}
 	}
-	return issue, nil
 }
 
 // EventWithIssue pairs an event with the issue ID it belongs to.
@@ -436,52 +636,47 @@ type EventWithIssue struct {
 }
 
 // LoadEvents reads all events from an issue's history.jsonl.
-// Returns empty slice (not error) if the file doesn't exist.
+// Returns empty slice (not error) if the file doesn't exist. Corrupt lines
+// are skipped and recorded as warnings unless t.Strict is set.
 func (t *Tracker) LoadEvents(issueID string) ([]model.Event, error) {
-	path := filepath.Join(t.Root, ".work", "issues", issueID, "history.jsonl")
-	f, err := os.Open(path)
+	return t.loadEvents(issueID, t.Strict)
+}
+
+// loadEventsForRewrite reads an issue's history for a caller that writes
+// it back. The read is always strict, since rewriting what a lenient read
+// kept would silently drop the corrupt lines doctor reports.
+func (t *Tracker) loadEventsForRewrite(issueID string) ([]model.Event, error) {
+	events, err := t.loadEvents(issueID, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w; run 'work doctor' before rewriting it", err)
+	}
+	return events, nil
+}
+
+func (t *Tracker) loadEvents(issueID string, strict bool) ([]model.Event, error) {
+	f, err := t.Store.OpenEvents(issueID)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("opening history: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	var events []model.Event
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		var ev model.Event
-		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
-			return nil, fmt.Errorf("parsing event: %w", err)
-		}
-		events = append(events, ev)
-	}
-	if err := scanner.Err(); err != nil {
+	file := "issues/" + issueID + "/history.jsonl"
+	events, warnings, err := decodeLines[model.Event](f, file, strict)
+	if err != nil {
 		return nil, fmt.Errorf("reading history: %w", err)
 	}
+	t.warn(warnings)
 	return events, nil
 }
 
 // LoadAllEvents reads events from every issue, annotated with issue ID.
 func (t *Tracker) LoadAllEvents() ([]EventWithIssue, error) {
-	issuesDir := filepath.Join(t.Root, ".work", "issues")
-	entries, err := os.ReadDir(issuesDir)
-	if err != nil {
-		return nil, fmt.Errorf("reading issues dir: %w", err)
-	}
 	var all []EventWithIssue
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
-		}
-		events, err := t.LoadEvents(e.Name())
+	for ev, err := range t.AllEvents() {
 		if err != nil {
 			return nil, err
 		}
-		for _, ev := range events {
-			all = append(all, EventWithIssue{Event: ev, IssueID: e.Name()})
-		}
+		all = append(all, ev)
 	}
 	return all, nil
 }
@@ -519,10 +714,24 @@ func FilterEventsWithIssueByTime(events []EventWithIssue, since, until time.Time
 
 // AddComment appends a comment to the issue and records a history event.
 func (t *Tracker) AddComment(id, text, user string) (model.Issue, error) {
-	issue, err := t.LoadIssue(id)
+	issue, event, err := t.addComment(id, text, user)
 	if err != nil {
 		return model.Issue{}, err
 	}
+	t.postHook(hookInput{Hook: HookPostComment, User: user, Issue: &issue, Event: &event})
+	return issue, nil
+}
+
+func (t *Tracker) addComment(id, text, user string) (model.Issue, model.Event, error) {
+	unlock, err := t.LockIssue(id)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	defer unlock()
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
 	now := time.Now().UTC()
 	comment := model.Comment{
 		Text:    text,
@@ -531,18 +740,19 @@ func (t *Tracker) AddComment(id, text, user string) (model.Issue, error) {
 	}
 	issue.Comments = append(issue.Comments, comment)
 	issue.Updated = now
-	if err := t.SaveIssue(issue); err != nil {
-		return model.Issue{}, err
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 	event := model.Event{
 		Timestamp: now,
 		Op:        "comment",
+		Text:      text,
 		By:        user,
 	}
 	if err := t.AppendEvent(id, event); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
-	return issue, nil
+	return issue, event, nil
 }
 
 // validateParent checks that the given parent ID exists and is not itself a child.
@@ -559,66 +769,97 @@ func (t *Tracker) validateParent(parentID string) error {
 
 // LinkIssue sets the parent of a child issue. Validates that the parent exists,
 // neither issue creates a grandchild relationship, and no circular ref.
+// Then applies the automation rules the change triggers.
 func (t *Tracker) LinkIssue(childID, parentID, user string) (model.Issue, error) {
+	child, event, err := t.linkIssue(childID, parentID, user)
+	if err != nil {
+		return model.Issue{}, err
+	}
+	return t.automateChange(child, []model.Event{event}, user)
+}
+
+func (t *Tracker) linkIssue(childID, parentID, user string) (model.Issue, model.Event, error) {
 	if childID == parentID {
-		return model.Issue{}, fmt.Errorf("cannot link issue to itself")
+		return model.Issue{}, model.Event{}, fmt.Errorf("cannot link issue to itself")
 	}
 
+	unlock, err := t.LockIssue(childID)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	defer unlock()
+
 	child, err := t.LoadIssue(childID)
 	if err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
 
 	if err := t.validateParent(parentID); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
 
 	// Child must not already be a parent (no grandchildren)
 	issues, err := t.ListIssues()
 	if err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
 	for _, issue := range issues {
 		if issue.ParentID == childID {
-			return model.Issue{}, fmt.Errorf("issue %s has children and cannot become a child", childID)
+			return model.Issue{}, model.Event{}, fmt.Errorf("issue %s has children and cannot become a child", childID)
 		}
 	}
 
 	now := time.Now().UTC()
+	oldParent := child.ParentID
 	child.ParentID = parentID
 	child.Updated = now
-	if err := t.SaveIssue(child); err != nil {
-		return model.Issue{}, err
+	if err := t.saveIssue(&child); err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 
 	event := model.Event{
 		Timestamp: now,
 		Op:        "link",
+		From:      oldParent,
 		To:        parentID,
 		By:        user,
 	}
 	if err := t.AppendEvent(childID, event); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
-	return child, nil
+	return child, event, nil
 }
 
-// UnlinkIssue removes the parent from a child issue.
+// UnlinkIssue removes the parent from a child issue, then applies the
+// automation rules the change triggers.
 func (t *Tracker) UnlinkIssue(childID, user string) (model.Issue, error) {
-	child, err := t.LoadIssue(childID)
+	child, event, err := t.unlinkIssue(childID, user)
 	if err != nil {
 		return model.Issue{}, err
 	}
+	return t.automateChange(child, []model.Event{event}, user)
+}
+
+func (t *Tracker) unlinkIssue(childID, user string) (model.Issue, model.Event, error) {
+	unlock, err := t.LockIssue(childID)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
+	defer unlock()
+	child, err := t.LoadIssue(childID)
+	if err != nil {
+		return model.Issue{}, model.Event{}, err
+	}
 	if child.ParentID == "" {
-		return model.Issue{}, fmt.Errorf("issue %s has no parent", childID)
+		return model.Issue{}, model.Event{}, fmt.Errorf("issue %s has no parent", childID)
 	}
 
 	now := time.Now().UTC()
 	oldParent := child.ParentID
 	child.ParentID = ""
 	child.Updated = now
-	if err := t.SaveIssue(child); err != nil {
-		return model.Issue{}, err
+	if err := t.saveIssue(&child); err != nil {
+		return model.Issue{}, model.Event{}, err
 	}
 
 	event := model.Event{
@@ -628,24 +869,15 @@ func (t *Tracker) UnlinkIssue(childID, user string) (model.Issue, error) {
 		By:        user,
 	}
 	if err := t.AppendEvent(childID, event); err != nil {
-		return model.Issue{}, err
+		return model.Issue{}, model.Event{}, err
 	}
-	return child, nil
+	return child, event, nil
 }
 
 // ListIssues loads all issues from the tracker.
 func (t *Tracker) ListIssues() ([]model.Issue, error) {
-	issuesDir := filepath.Join(t.Root, ".work", "issues")
-	entries, err := os.ReadDir(issuesDir)
-	if err != nil {
-		return nil, fmt.Errorf("reading issues dir: %w", err)
-	}
 	var issues []model.Issue
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
-		}
-		issue, err := t.LoadIssue(e.Name())
+	for issue, err := range t.Issues() {
 		if err != nil {
 			return nil, err
 		}
@@ -663,11 +895,35 @@ type LogEntry struct {
 	Labels  []string  `json:"labels,omitempty"`
 	Created time.Time `json:"created"`
 	Closed  time.Time `json:"closed"`
+	// Archive names the .work/archive/ file holding the issue's full
+	// directory, if it was archived rather than deleted.
+	Archive string `json:"archive,omitempty"`
+}
+
+func newLogEntry(issue model.Issue) LogEntry {
+	return LogEntry{
+		ID:      issue.ID,
+		Title:   issue.Title,
+		Type:    issue.Type,
+		Status:  issue.Status,
+		Labels:  issue.Labels,
+		Created: issue.Created,
+		Closed:  issue.Updated,
+	}
 }
 
 // AppendLog writes a one-line JSON entry to .work/log.jsonl.
 // Skips writing if the issue ID already exists in the log.
 func (t *Tracker) AppendLog(issue model.Issue) error {
+	return t.appendLog(issue, false)
+}
+
+func (t *Tracker) appendLog(issue model.Issue, trackerHeld bool) error {
+	unlock, err := t.lockLog(trackerHeld)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	existing, err := t.LoadLog()
 	if err != nil {
 		return fmt.Errorf("reading existing log: %w", err)
@@ -678,33 +934,63 @@ func (t *Tracker) AppendLog(issue model.Issue) error {
 		}
 	}
 
-	entry := LogEntry{
-		ID:      issue.ID,
-		Title:   issue.Title,
-		Type:    issue.Type,
-		Status:  issue.Status,
-		Labels:  issue.Labels,
-		Created: issue.Created,
-		Closed:  issue.Updated,
+	data, err := json.Marshal(newLogEntry(issue))
+	if err != nil {
+		return fmt.Errorf("marshaling log entry: %w", err)
 	}
-	path := filepath.Join(t.Root, ".work", "log.jsonl")
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	return t.Store.AppendLog(data)
+}
+
+// setLogEntries writes entries to the log, replacing any existing
+// entries for the same issues in place and appending the rest. Callers
+// hold the tracker-wide lock.
+func (t *Tracker) setLogEntries(entries []LogEntry) error {
+	existing, err := t.loadLogForRewrite()
 	if err != nil {
-		return fmt.Errorf("opening log: %w", err)
+		return fmt.Errorf("reading existing log: %w", err)
 	}
-	defer func() { _ = f.Close() }()
-	data, err := json.Marshal(entry)
+	byID := make(map[string]LogEntry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	written := make(map[string]bool, len(entries))
+	var merged []LogEntry
+	for _, e := range existing {
+		if replacement, ok := byID[e.ID]; ok {
+			if written[e.ID] {
+				continue
+			}
+			e = replacement
+			written[e.ID] = true
+		}
+		merged = append(merged, e)
+	}
+	for _, e := range entries {
+		if !written[e.ID] {
+			merged = append(merged, e)
+			written[e.ID] = true
+		}
+	}
+	data, err := marshalLines(merged)
 	if err != nil {
-		return fmt.Errorf("marshaling log entry: %w", err)
+		return fmt.Errorf("marshaling: %w", err)
 	}
-	_, err = fmt.Fprintf(f, "%s\n", data)
-	return err
+	return t.Store.WriteLog(data)
 }
 
 // DeduplicateLog removes duplicate entries from log.jsonl, keeping the first
 // occurrence of each issue ID. Returns the number of duplicates removed.
 func (t *Tracker) DeduplicateLog() (int, error) {
-	entries, err := t.LoadLog()
+	return t.deduplicateLog(false)
+}
+
+func (t *Tracker) deduplicateLog(trackerHeld bool) (int, error) {
+	unlock, err := t.lockLog(trackerHeld)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	entries, err := t.loadLogForRewrite()
 	if err != nil {
 		return 0, err
 	}
@@ -720,47 +1006,40 @@ func (t *Tracker) DeduplicateLog() (int, error) {
 	if removed == 0 {
 		return 0, nil
 	}
-	path := filepath.Join(t.Root, ".work", "log.jsonl")
-	f, err := os.Create(path)
+	data, err := marshalLines(unique)
 	if err != nil {
-		return 0, fmt.Errorf("rewriting log: %w", err)
+		return 0, fmt.Errorf("marshaling: %w", err)
 	}
-	defer func() { _ = f.Close() }()
-	for _, e := range unique {
-		data, err := json.Marshal(e)
-		if err != nil {
-			return 0, fmt.Errorf("marshaling: %w", err)
-		}
-		if _, err := fmt.Fprintf(f, "%s\n", data); err != nil {
-			return 0, fmt.Errorf("writing log entry: %w", err)
-		}
+	if err := t.Store.WriteLog(data); err != nil {
+		return 0, fmt.Errorf("rewriting log: %w", err)
 	}
 	return removed, nil
 }
 
-// LoadLog reads all entries from .work/log.jsonl.
+// LoadLog reads all entries from .work/log.jsonl. Corrupt lines are
+// skipped and recorded as warnings unless t.Strict is set.
 func (t *Tracker) LoadLog() ([]LogEntry, error) {
-	path := filepath.Join(t.Root, ".work", "log.jsonl")
-	f, err := os.Open(path)
+	return t.loadLog(t.Strict)
+}
+
+// loadLogForRewrite reads the log strictly for a caller that writes it
+// back; see loadEventsForRewrite.
+func (t *Tracker) loadLogForRewrite() ([]LogEntry, error) {
+	entries, err := t.loadLog(true)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("opening log: %w", err)
+		return nil, fmt.Errorf("%w; run 'work doctor' before rewriting it", err)
 	}
-	defer func() { _ = f.Close() }()
+	return entries, nil
+}
+
+func (t *Tracker) loadLog(strict bool) ([]LogEntry, error) {
 	var entries []LogEntry
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		var entry LogEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			return nil, fmt.Errorf("parsing log entry: %w", err)
+	for entry, err := range t.logEntries(strict) {
+		if err != nil {
+			return nil, err
 		}
 		entries = append(entries, entry)
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading log: %w", err)
-	}
 	return entries, nil
 }
 
@@ -768,18 +1047,38 @@ func (t *Tracker) LoadLog() ([]LogEntry, error) {
 // Logs to .work/log.jsonl, truncates description, clears comments,
 // and compacts history to create + close events only.
 func (t *Tracker) CompactIssue(id string) error {
+	unlock, err := t.LockIssue(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	issue, err := t.LoadIssue(id)
 	if err != nil {
 		return err
 	}
-	if issue.Status != "done" && issue.Status != "cancelled" {
-		return fmt.Errorf("can only compact done/cancelled issues (current: %s)", issue.Status)
+	if !t.Config.IsTerminal(issue.Status) {
+		return fmt.Errorf("can only compact issues in a terminal state (current: %s)", issue.Status)
+	}
+	events, err := t.loadEventsForRewrite(id)
+	if err != nil {
+		return err
 	}
 
 	if err := t.AppendLog(issue); err != nil {
 		return fmt.Errorf("appending to log: %w", err)
 	}
 
+	compactFields(&issue)
+	if err := t.saveIssue(&issue); err != nil {
+		return err
+	}
+
+	return t.compactHistory(issue, events)
+}
+
+// compactFields strips an issue to what compaction keeps: the first line
+// of the description, up to 120 bytes, and no comments.
+func compactFields(issue *model.Issue) {
 	if desc := issue.Description; desc != "" {
 		if idx := strings.IndexByte(desc, '\n'); idx >= 0 {
 			issue.Description = desc[:idx]
@@ -788,20 +1087,14 @@ func (t *Tracker) CompactIssue(id string) error {
 			issue.Description = issue.Description[:120]
 		}
 	}
-
 	issue.Comments = nil
-	if err := t.SaveIssue(issue); err != nil {
-		return err
-	}
-
-	return t.compactHistory(id)
 }
 
-func (t *Tracker) compactHistory(id string) error {
-	events, err := t.LoadEvents(id)
-	if err != nil {
-		return err
-	}
+// compactHistory rewrites an issue's history, events, to its create and
+// closing events. The last kept event is marked Compacted and carries a
+// snapshot of the compacted issue, so the history can still be replayed.
+func (t *Tracker) compactHistory(issue model.Issue, events []model.Event) error {
+	id := issue.ID
 	if len(events) == 0 {
 		return nil
 	}
@@ -809,46 +1102,61 @@ func (t *Tracker) compactHistory(id string) error {
 	var compacted []model.Event
 	compacted = append(compacted, events[0])
 	for i := len(events) - 1; i > 0; i-- {
-		if events[i].Op == "status" && (events[i].To == "done" || events[i].To == "cancelled") {
+		if events[i].Op == "status" && t.Config.IsTerminal(events[i].To) {
 			compacted = append(compacted, events[i])
 			break
 		}
 	}
+	snapshot := issue
+	snapshot.Revision = 0
+	compacted[len(compacted)-1].Compacted = true
+	compacted[len(compacted)-1].Issue = &snapshot
 
-	path := filepath.Join(t.Root, ".work", "issues", id, "history.jsonl")
-	f, err := os.Create(path)
+	data, err := marshalLines(compacted)
 	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+	if err := t.Store.WriteEvents(id, data); err != nil {
 		return fmt.Errorf("rewriting history: %w", err)
 	}
-	defer func() { _ = f.Close() }()
-	for _, ev := range compacted {
-		data, err := json.Marshal(ev)
+	return nil
+}
+
+// marshalLines encodes items as newline-terminated JSON lines.
+func marshalLines[T any](items []T) ([]byte, error) {
+	var buf []byte
+	for _, item := range items {
+		data, err := json.Marshal(item)
 		if err != nil {
-			return fmt.Errorf("marshaling event: %w", err)
-		}
-		if _, err := fmt.Fprintf(f, "%s\n", data); err != nil {
-			return fmt.Errorf("writing event: %w", err)
+			return nil, err
 		}
+		buf = append(buf, data...)
+		buf = append(buf, '\n')
 	}
-	return nil
+	return buf, nil
 }
 
 // RewriteAllIssues loads every issue and re-saves it, migrating to the current
 // on-disk format (e.g. compact JSON).
 func (t *Tracker) RewriteAllIssues() (int, error) {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
 	issues, err := t.ListIssues()
 	if err != nil {
 		return 0, err
 	}
 	for _, issue := range issues {
-		if err := t.SaveIssue(issue); err != nil {
+		if err := t.saveIssue(&issue); err != nil {
 			return 0, fmt.Errorf("rewriting %s: %w", issue.ID, err)
 		}
 	}
 	return len(issues), nil
 }
 
-// CompactAllDone compacts all done/cancelled issues.
+// CompactAllDone compacts all issues in a terminal state.
 func (t *Tracker) CompactAllDone() ([]string, error) {
 	issues, err := t.ListIssues()
 	if err != nil {
@@ -856,7 +1164,7 @@ func (t *Tracker) CompactAllDone() ([]string, error) {
 	}
 	var compacted []string
 	for _, issue := range issues {
-		if issue.Status == "done" || issue.Status == "cancelled" {
+		if t.Config.IsTerminal(issue.Status) {
 			if err := t.CompactIssue(issue.ID); err != nil {
 				return compacted, fmt.Errorf("compacting %s: %w", issue.ID, err)
 			}
@@ -866,13 +1174,21 @@ func (t *Tracker) CompactAllDone() ([]string, error) {
 	return compacted, nil
 }
 
-// PurgeIssue logs an issue to the completion log and removes its directory.
+// PurgeIssue logs an issue to the completion log and removes it from the store.
 func (t *Tracker) PurgeIssue(issue model.Issue) error {
-	if err := t.AppendLog(issue); err != nil {
+	return t.purgeIssue(issue, false)
+}
+
+func (t *Tracker) purgeIssue(issue model.Issue, trackerHeld bool) error {
+	unlock, err := t.lockIssue(issue.ID, trackerHeld)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := t.appendLog(issue, trackerHeld); err != nil {
 		return fmt.Errorf("logging %s: %w", issue.ID, err)
 	}
-	dir := filepath.Join(t.Root, ".work", "issues", issue.ID)
-	if err := os.RemoveAll(dir); err != nil {
+	if err := t.Store.RemoveIssue(issue.ID); err != nil {
 		return fmt.Errorf("removing %s: %w", issue.ID, err)
 	}
 	return nil
@@ -881,21 +1197,92 @@ func (t *Tracker) PurgeIssue(issue model.Issue) error {
 // GarbageCollect removes issue directories for issues completed
 // more than maxAgeDays ago. Logs each issue before deletion.
 func (t *Tracker) GarbageCollect(maxAgeDays int) ([]string, error) {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 	issues, err := t.ListIssues()
 	if err != nil {
 		return nil, err
 	}
 	cutoff := time.Now().UTC().AddDate(0, 0, -maxAgeDays)
+	var old []model.Issue
+	for _, issue := range issues {
+		if t.Config.IsTerminal(issue.Status) && issue.Updated.Before(cutoff) {
+			old = append(old, issue)
+		}
+	}
+	if len(old) == 0 {
+		return nil, nil
+	}
+	if err := t.PreGC(old); err != nil {
+		return nil, err
+	}
 	var purged []string
+	for _, issue := range old {
+		if err := t.purgeIssue(issue, true); err != nil {
+			return purged, err
+		}
+		purged = append(purged, issue.ID)
+	}
+	return purged, nil
+}
+
+// CollectIssues removes the issues pick chooses from those in a terminal
+// state, given newest first: it purges them, or with archive set archives
+// them, then deduplicates the log. Choosing and removing happen under
+// one hold of the tracker-wide lock, so nothing can change in between;
+// the pre-gc hook runs once they are chosen. Returns the removed IDs and
+// the number of duplicate log entries removed.
+func (t *Tracker) CollectIssues(pick func(completed []model.Issue) []model.Issue, archive bool) ([]string, int, error) {
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer unlock()
+	issues, err := t.ListIssues()
+	if err != nil {
+		return nil, 0, err
+	}
+	var completed []model.Issue
 	for _, issue := range issues {
-		if (issue.Status == "done" || issue.Status == "cancelled") && issue.Updated.Before(cutoff) {
-			if err := t.PurgeIssue(issue); err != nil {
-				return purged, err
+		if t.Config.IsTerminal(issue.Status) {
+			completed = append(completed, issue)
+		}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Updated.After(completed[j].Updated)
+	})
+	chosen := pick(completed)
+	if len(chosen) == 0 {
+		return nil, 0, nil
+	}
+	if err := t.PreGC(chosen); err != nil {
+		return nil, 0, err
+	}
+
+	var removed []string
+	if archive {
+		if err := t.archiveIssues(chosen); err != nil {
+			return nil, 0, err
+		}
+		for _, issue := range chosen {
+			removed = append(removed, issue.ID)
+		}
+	} else {
+		for _, issue := range chosen {
+			if err := t.purgeIssue(issue, true); err != nil {
+				return removed, 0, err
 			}
-			purged = append(purged, issue.ID)
+			removed = append(removed, issue.ID)
 		}
 	}
-	return purged, nil
+	deduped, err := t.deduplicateLog(true)
+	if err != nil {
+		return removed, 0, fmt.Errorf("deduplicating log: %w", err)
+	}
+	return removed, deduped, nil
 }
 
 // IsHexID returns true if the ID consists only of hex characters (old format).
@@ -908,24 +1295,25 @@ func IsHexID(id string) bool {
 	return len(id) > 0
 }
 
-// RehashIssue assigns a new Crockford Base32 ID to an issue, renaming its
-// directory and updating all references (ParentID in children, log entries).
-// Returns the new ID.
+// RehashIssue assigns a new ID in the configured scheme to an issue,
+// renaming its directory and updating all references (ParentID in
+// children, log entries). Returns the new ID.
 func (t *Tracker) RehashIssue(oldID string) (string, error) {
-	newID, err := t.GenerateID()
+	unlock, err := t.LockTracker()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	next, err := t.idGenerator()
 	if err != nil {
 		return "", err
 	}
-	return newID, t.renameIssue(oldID, newID)
+	return t.rehash(oldID, next)
 }
 
 func (t *Tracker) renameIssue(oldID, newID string) error {
-	issuesDir := filepath.Join(t.Root, ".work", "issues")
-
 	// Rename the directory
-	oldDir := filepath.Join(issuesDir, oldID)
-	newDir := filepath.Join(issuesDir, newID)
-	if err := os.Rename(oldDir, newDir); err != nil {
+	if err := t.Store.RenameIssue(oldID, newID); err != nil {
 		return fmt.Errorf("renaming directory: %w", err)
 	}
 
@@ -933,30 +1321,35 @@ func (t *Tracker) renameIssue(oldID, newID string) error {
 	issue, err := t.LoadIssue(newID)
 	if err != nil {
 		// Rollback directory rename
-		_ = os.Rename(newDir, oldDir)
+		_ = t.Store.RenameIssue(newID, oldID)
 		return err
 	}
 	issue.ID = newID
-	if err := t.SaveIssue(issue); err != nil {
+	if err := t.saveIssue(&issue); err != nil {
 		return err
 	}
 
 	// Update ParentID references in all other issues
-	entries, err := os.ReadDir(issuesDir)
+	ids, err := t.Store.ListIssueIDs()
 	if err != nil {
 		return fmt.Errorf("reading issues dir: %w", err)
 	}
-	for _, e := range entries {
-		if !e.IsDir() || e.Name() == newID {
+	for _, id := range ids {
+		if id == newID {
 			continue
 		}
-		child, err := t.LoadIssue(e.Name())
+		child, err := t.LoadIssue(id)
 		if err != nil {
 			continue
 		}
 		if child.ParentID == oldID {
 			child.ParentID = newID
-			if err := t.SaveIssue(child); err != nil {
+			if err := t.saveIssue(&child); err != nil {
+				return fmt.Errorf("updating child %s: %w", child.ID, err)
+			}
+			// Keep the child's history replayable to its new parent ID.
+			event := model.Event{Timestamp: time.Now().UTC(), Op: "link", From: oldID, To: newID}
+			if err := t.AppendEvent(child.ID, event); err != nil {
 				return fmt.Errorf("updating child %s: %w", child.ID, err)
 			}
 		}
@@ -970,8 +1363,10 @@ func (t *Tracker) renameIssue(oldID, newID string) error {
 	return nil
 }
 
+// rewriteLogID points the log entries for oldID at newID. Callers hold
+// the tracker-wide lock.
 func (t *Tracker) rewriteLogID(oldID, newID string) error {
-	entries, err := t.LoadLog()
+	entries, err := t.loadLogForRewrite()
 	if err != nil || len(entries) == 0 {
 		return err
 	}
@@ -985,20 +1380,9 @@ func (t *Tracker) rewriteLogID(oldID, newID string) error {
 	if !changed {
 		return nil
 	}
-	path := filepath.Join(t.Root, ".work", "log.jsonl")
-	f, err := os.Create(path)
+	data, err := marshalLines(entries)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = f.Close() }()
-	for _, e := range entries {
-		data, err := json.Marshal(e)
-		if err != nil {
-			return err
-		}
-		if _, err := fmt.Fprintf(f, "%s\n", data); err != nil {
-			return err
-		}
-	}
-	return nil
+	return t.Store.WriteLog(data)
 }
@@ -3,9 +3,11 @@ package tracker
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -178,6 +180,8 @@ func TestInit(t *testing.T) {
 		".work/issues/** linguist-generated diff=work",
 		".work/log.jsonl linguist-generated diff=work",
 		".work/config.json linguist-generated diff=work",
+		".work/issues/*/issue.json merge=work",
+		".work/log.jsonl merge=work",
 	} {
 		if !strings.Contains(gaContent, want) {
 			t.Errorf(".gitattributes missing %q", want)
@@ -209,6 +213,8 @@ func TestInit_AppendsToExistingGitattributes(t *testing.T) {
 		".work/issues/** linguist-generated diff=work",
 		".work/log.jsonl linguist-generated diff=work",
 		".work/config.json linguist-generated diff=work",
+		".work/issues/*/issue.json merge=work",
+		".work/log.jsonl merge=work",
 	} {
 		if !strings.Contains(content, want) {
 			t.Errorf("missing %q", want)
@@ -277,6 +283,55 @@ func TestInit_PreservesExistingConfig(t *testing.T) {
 	}
 }
 
+func TestLoad_LegacyStatesList(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Init(root); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	// Older trackers listed state names under "states".
+	legacy := `{
+  "states": ["open", "active", "blocked", "done"],
+  "transitions": {"open": ["active"], "active": ["blocked", "done"], "blocked": ["active"], "done": ["open"]},
+  "default_state": "open",
+  "types": ["feature", "bug", "chore"],
+  "default_type": "feature",
+  "id_length": 6
+}`
+	cfgPath := filepath.Join(root, ".work", "config.json")
+	if err := os.WriteFile(cfgPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	tr, err := Load(root)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !tr.Config.IsTerminal("done") || tr.Config.IsTerminal("blocked") {
+		t.Errorf("states = %v, want done terminal and blocked not", tr.Config.States)
+	}
+	if got := tr.Config.Category("active"); got != model.CategoryInProgress {
+		t.Errorf("active category = %q, want %q", got, model.CategoryInProgress)
+	}
+	if _, err := tr.Migrate(false); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// The migrated config is written in the current form.
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	var raw struct {
+		States map[string]model.StateConfig `json:"states"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("migrated config: %v", err)
+	}
+	if raw.States["blocked"].Category != model.CategoryBacklog {
+		t.Errorf("migrated states = %v", raw.States)
+	}
+}
+
 func TestIssueSaveLoad(t *testing.T) {
 	root := t.TempDir()
 	tr, err := Init(root)
@@ -598,7 +653,7 @@ func TestValidateTransition_Valid(t *testing.T) {
 		{"cancelled", "open"},
 	}
 	for _, pair := range valid {
-		if err := ValidateTransition(cfg, pair[0], pair[1]); err != nil {
+		if err := ValidateTransition(cfg, "", pair[0], pair[1]); err != nil {
 			t.Errorf("%s→%s: unexpected error: %v", pair[0], pair[1], err)
 		}
 	}
@@ -615,7 +670,7 @@ func TestValidateTransition_Invalid(t *testing.T) {
 		{"active", "active"},
 	}
 	for _, pair := range invalid {
-		err := ValidateTransition(cfg, pair[0], pair[1])
+		err := ValidateTransition(cfg, "", pair[0], pair[1])
 		if err == nil {
 			t.Errorf("%s→%s: expected error, got nil", pair[0], pair[1])
 		}
@@ -627,7 +682,7 @@ func TestValidateTransition_Invalid(t *testing.T) {
 
 func TestValidateTransition_UnknownState(t *testing.T) {
 	cfg := model.DefaultConfig()
-	err := ValidateTransition(cfg, "nonexistent", "open")
+	err := ValidateTransition(cfg, "", "nonexistent", "open")
 	if err == nil {
 		t.Fatal("expected error for unknown state")
 	}
@@ -811,19 +866,19 @@ func TestValidateTransition_CustomConfig(t *testing.T) {
 	}
 
 	// Valid custom transitions
-	if err := ValidateTransition(cfg, "todo", "doing"); err != nil {
+	if err := ValidateTransition(cfg, "", "todo", "doing"); err != nil {
 		t.Errorf("todo→doing: %v", err)
 	}
-	if err := ValidateTransition(cfg, "review", "done"); err != nil {
+	if err := ValidateTransition(cfg, "", "review", "done"); err != nil {
 		t.Errorf("review→done: %v", err)
 	}
 
 	// Invalid in custom config
-	err := ValidateTransition(cfg, "todo", "review")
+	err := ValidateTransition(cfg, "", "todo", "review")
 	if err == nil {
 		t.Error("expected error for todo→review")
 	}
-	err = ValidateTransition(cfg, "todo", "done")
+	err = ValidateTransition(cfg, "", "todo", "done")
 	if err == nil {
 		t.Error("expected error for todo→done")
 	}
@@ -1231,7 +1286,7 @@ func TestRejectWorkflow(t *testing.T) {
 
 func TestReviewTransition_InvalidFromOpen(t *testing.T) {
 	cfg := model.DefaultConfig()
-	err := ValidateTransition(cfg, "open", "review")
+	err := ValidateTransition(cfg, "", "open", "review")
 	if err == nil {
 		t.Error("expected error for open→review")
 	}
@@ -1239,7 +1294,7 @@ func TestReviewTransition_InvalidFromOpen(t *testing.T) {
 
 func TestReviewTransition_InvalidFromDone(t *testing.T) {
 	cfg := model.DefaultConfig()
-	err := ValidateTransition(cfg, "done", "review")
+	err := ValidateTransition(cfg, "", "done", "review")
 	if err == nil {
 		t.Error("expected error for done→review")
 	}
@@ -1872,7 +1927,7 @@ func TestCompactIssue_OnlyDone(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error compacting active issue")
 	}
-	if !strings.Contains(err.Error(), "can only compact done/cancelled") {
+	if !strings.Contains(err.Error(), "can only compact issues in a terminal state") {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
@@ -2096,7 +2151,104 @@ func TestCompactAllDone(t *testing.T) {
 	}
 }
 
-func TestAddComment_NoTextInEvent(t *testing.T) {
+func TestCustomWorkflowStates(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.Transitions = map[string][]string{
+		"todo":    {"blocked", "doing", "wontfix"},
+		"blocked": {"todo"},
+		"doing":   {"qa", "blocked"},
+		"qa":      {"shipped", "doing"},
+		"shipped": {"todo"},
+		"wontfix": {"todo"},
+	}
+	cfg.DefaultState = "todo"
+	cfg.States = map[string]model.StateConfig{
+		"todo":    {Category: model.CategoryBacklog},
+		"blocked": {Category: model.CategoryBacklog},
+		"doing":   {Category: model.CategoryInProgress},
+		"qa":      {Category: model.CategoryReview},
+		"shipped": {Category: model.CategoryDone, Terminal: true},
+		"wontfix": {Category: model.CategoryCancelled, Terminal: true},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	want := []string{"blocked", "todo", "doing", "qa", "shipped", "wontfix"}
+	if got := cfg.StateNames(); !slices.Equal(got, want) {
+		t.Errorf("StateNames = %v, want %v", got, want)
+	}
+	if got := cfg.TerminalStates(); !slices.Equal(got, []string{"shipped", "wontfix"}) {
+		t.Errorf("TerminalStates = %v", got)
+	}
+
+	blocked, err := tr.CreateIssue("Blocked", "keep me", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(blocked.ID, "blocked", "testuser"); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if err := tr.CompactIssue(blocked.ID); err == nil {
+		t.Error("compacted a non-terminal state")
+	}
+
+	dropped, err := tr.CreateIssue("Dropped", "content", "", 0, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(dropped.ID, "wontfix", "testuser"); err != nil {
+		t.Fatalf("wontfix: %v", err)
+	}
+	compacted, err := tr.CompactAllDone()
+	if err != nil {
+		t.Fatalf("compact all: %v", err)
+	}
+	if !slices.Equal(compacted, []string{dropped.ID}) {
+		t.Errorf("compacted = %v, want [%s]", compacted, dropped.ID)
+	}
+	events, err := tr.LoadEvents(dropped.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if last := events[len(events)-1]; last.Op != "status" || last.To != "wontfix" || !last.Compacted {
+		t.Errorf("kept closing event = %+v", last)
+	}
+
+	// Backdate so gc considers it.
+	issue, err := tr.LoadIssue(dropped.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	issue.Updated = time.Now().UTC().AddDate(0, 0, -60)
+	if err := tr.SaveIssue(issue); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	purged, err := tr.GarbageCollect(30)
+	if err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if !slices.Equal(purged, []string{dropped.ID}) {
+		t.Errorf("purged = %v, want [%s]", purged, dropped.ID)
+	}
+}
+
+func TestConfigState_BuiltinFallback(t *testing.T) {
+	// Configs written before states were declared.
+	cfg := model.DefaultConfig()
+	cfg.States = nil
+	for state, terminal := range map[string]bool{"open": false, "active": false, "review": false, "done": true, "cancelled": true, "blocked": false} {
+		if got := cfg.IsTerminal(state); got != terminal {
+			t.Errorf("IsTerminal(%q) = %v, want %v", state, got, terminal)
+		}
+	}
+	if got := cfg.Category("active"); got != model.CategoryInProgress {
+		t.Errorf("Category(active) = %q", got)
+	}
+	if got := cfg.Category("blocked"); got != model.CategoryBacklog {
+		t.Errorf("Category(blocked) = %q", got)
+	}
+}
+
+func TestAddComment_TextInEvent(t *testing.T) {
 	root := t.TempDir()
 	tr, err := Init(root)
 	if err != nil {
@@ -2121,14 +2273,14 @@ func TestAddComment_NoTextInEvent(t *testing.T) {
 		t.Errorf("comment text: got %q", loaded.Comments[0].Text)
 	}
 
-	// But NOT in history.jsonl event
+	// And in the history.jsonl event, so the issue can be replayed
 	events, err := tr.LoadEvents(issue.ID)
 	if err != nil {
 		t.Fatalf("load events: %v", err)
 	}
 	commentEvent := events[1]
-	if commentEvent.Text != "" {
-		t.Errorf("event should not have Text, got %q", commentEvent.Text)
+	if commentEvent.Text != "hello world" {
+		t.Errorf("event Text: got %q, want %q", commentEvent.Text, "hello world")
 	}
 	if commentEvent.By != "testuser" {
 		t.Errorf("event By: got %q", commentEvent.By)
@@ -2308,3 +2460,112 @@ func readEvents(t *testing.T, path string) []model.Event {
 	}
 	return events
 }
+
+func TestPerTypeWorkflows(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.States["triaged"] = model.StateConfig{Category: model.CategoryBacklog}
+	cfg.States["verify"] = model.StateConfig{Category: model.CategoryReview}
+	cfg.Workflows = map[string]model.Workflow{
+		"bug": {Transitions: map[string][]string{
+			"open":    {"triaged", "cancelled"},
+			"triaged": {"active", "cancelled"},
+			"active":  {"verify", "triaged"},
+			"verify":  {"done", "active"},
+			"done":    {"open"},
+		}},
+		"chore": {Transitions: map[string][]string{
+			"todo": {"done"},
+			"done": {"todo"},
+		}, DefaultState: "todo"},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	bug, err := tr.CreateIssue("Crash", "", "", 1, nil, "bug", "", "testuser")
+	if err != nil {
+		t.Fatalf("create bug: %v", err)
+	}
+	chore, err := tr.CreateIssue("Tidy", "", "", 3, nil, "chore", "", "testuser")
+	if err != nil {
+		t.Fatalf("create chore: %v", err)
+	}
+	if bug.Status != "open" || chore.Status != "todo" {
+		t.Errorf("initial states = %q, %q; want open, todo", bug.Status, chore.Status)
+	}
+	if err := ValidateTransition(cfg, "bug", "open", "active"); err == nil {
+		t.Error("bug skipped triage")
+	}
+	if err := ValidateTransition(cfg, "feature", "open", "active"); err != nil {
+		t.Errorf("feature falls back to global transitions: %v", err)
+	}
+	if _, err := tr.SetStatus(chore.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close chore: %v", err)
+	}
+	if !slices.Contains(cfg.StateNames(), "verify") {
+		t.Errorf("StateNames = %v, lacks workflow states", cfg.StateNames())
+	}
+
+	t.Run("shortcut", func(t *testing.T) {
+		bug, _ := tr.LoadIssue(bug.ID)
+		if got := ShortcutState(cfg, bug, "cancelled"); got != "cancelled" {
+			t.Errorf("cancel from open = %q", got)
+		}
+		bug.Status = "active"
+		if got := ShortcutState(cfg, bug, "review"); got != "verify" {
+			t.Errorf("review from active = %q, want verify", got)
+		}
+		if got := ShortcutState(cfg, bug, "done"); got != "done" {
+			t.Errorf("close from active = %q, want done to be rejected by SetStatus", got)
+		}
+	})
+
+	t.Run("type change", func(t *testing.T) {
+		if _, err := tr.SetStatus(bug.ID, "triaged", "testuser"); err != nil {
+			t.Fatalf("triage: %v", err)
+		}
+		base, _ := tr.LoadIssue(bug.ID)
+		edited := base
+		edited.Type = "chore"
+		_, _, err := tr.SaveEdit(base, edited, "testuser")
+		var mapping *StateMappingError
+		if !errors.As(err, &mapping) {
+			t.Fatalf("unmapped type change: got %v, want StateMappingError", err)
+		}
+		if mapping.Status != "triaged" || !slices.Equal(mapping.States, []string{"todo", "done"}) {
+			t.Errorf("mapping error = %+v", mapping)
+		}
+
+		edited.Status = "todo"
+		saved, _, err := tr.SaveEdit(base, edited, "testuser")
+		if err != nil {
+			t.Fatalf("mapped type change: %v", err)
+		}
+		if saved.Type != "chore" || saved.Status != "todo" {
+			t.Errorf("saved = %s/%s, want chore/todo", saved.Type, saved.Status)
+		}
+		events, _ := tr.LoadEvents(bug.ID)
+		if last := events[len(events)-1]; last.Op != "status" || last.From != "triaged" || last.To != "todo" {
+			t.Errorf("last event = %+v, want status triaged → todo", last)
+		}
+		replayed, err := tr.ReplayIssue(bug.ID)
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if mismatches, err := ReplayMismatches(saved, replayed); err != nil || len(mismatches) != 0 {
+			t.Errorf("replay after type change: %v, %v", mismatches, err)
+		}
+
+		// todo → triaged is no chore transition; the change and its
+		// mapping are undone together and checked against bug.
+		undone, err := tr.UndoIssue(bug.ID, 1, "testuser")
+		if err != nil {
+			t.Fatalf("undo mapped type change: %v", err)
+		}
+		if len(undone) != 2 {
+			t.Errorf("undo wrote %d events, want 2", len(undone))
+		}
+		restored, _ := tr.LoadIssue(bug.ID)
+		if restored.Type != "bug" || restored.Status != "triaged" {
+			t.Errorf("restored = %s/%s, want bug/triaged", restored.Type, restored.Status)
+		}
+	})
+}
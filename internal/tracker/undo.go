@@ -0,0 +1,238 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// ErrNothingToUndo is returned when an issue has fewer undoable events
+// than requested.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// undoTargets returns the latest steps events of history that have not
+// already been undone, newest first, as indices into events grouped by
+// step. Each compensating event cancels the latest earlier event that
+// isn't itself cancelled.
+func undoTargets(events []model.Event, steps int) ([][]int, error) {
+	var targets [][]int
+	pending := 0
+	for i := len(events) - 1; i >= 0 && len(targets) < steps; i-- {
+		ev := events[i]
+		if ev.Undo {
+			pending++
+			continue
+		}
+		if ev.Compacted {
+			return nil, fmt.Errorf("cannot undo past compaction at %s: earlier history was discarded",
+				ev.Timestamp.Format(time.RFC3339))
+		}
+		if ev.Op == "restore" {
+			// Only a marker; there is nothing to revert.
+			continue
+		}
+		if pending > 0 {
+			pending--
+			continue
+		}
+		if ev.Op == "create" {
+			break
+		}
+		if isStateMapping(events, i) {
+			// The type change and the mapping it needed are one step.
+			targets = append(targets, []int{i, i - 1})
+			i--
+			continue
+		}
+		targets = append(targets, []int{i})
+	}
+	if len(targets) < steps {
+		return nil, fmt.Errorf("%w: only %d step(s) available", ErrNothingToUndo, len(targets))
+	}
+	return targets, nil
+}
+
+// isStateMapping reports whether events[i] is the status event SaveEdit
+// records, along with the edit before it, when a type change maps the
+// issue to a state of the new workflow.
+func isStateMapping(events []model.Event, i int) bool {
+	if i == 0 || events[i].Op != "status" {
+		return false
+	}
+	edit := events[i-1]
+	return edit.Op == "edit" && !edit.Undo && edit.Timestamp.Equal(events[i].Timestamp) &&
+		slices.Contains(edit.Fields, "type")
+}
+
+// revert applies the inverse of ev to issue and returns the compensating
+// event that records it. before is the history preceding ev. A status
+// event that mapped the state for a type change is not checked against
+// the workflow; the caller checks the restored state against the
+// restored type instead.
+func (t *Tracker) revert(issue *model.Issue, ev model.Event, before []model.Event, mapping bool, now time.Time, user string) (model.Event, error) {
+	undo := model.Event{Timestamp: now, By: user, Undo: true}
+	switch ev.Op {
+	case "status":
+		if issue.Status != ev.To {
+			return model.Event{}, fmt.Errorf("status is %s, expected %s from the event being undone", issue.Status, ev.To)
+		}
+		if !mapping {
+			if err := ValidateTransition(t.Config, issue.Type, ev.To, ev.From); err != nil {
+				return model.Event{}, err
+			}
+		}
+		issue.Status = ev.From
+		undo.Op, undo.From, undo.To = "status", ev.To, ev.From
+
+	case "edit":
+		if len(ev.Changes) == 0 {
+			return model.Event{}, fmt.Errorf("edit at %s has no recorded values to restore",
+				ev.Timestamp.Format(time.RFC3339))
+		}
+		undo.Op = "edit"
+		for _, c := range ev.Changes {
+			if err := setField(issue, c.Field, c.From); err != nil {
+				return model.Event{}, err
+			}
+			undo.Fields = append(undo.Fields, c.Field)
+			undo.Changes = append(undo.Changes, model.FieldChange{Field: c.Field, From: c.To, To: c.From})
+		}
+
+	case "link", "unlink":
+		// Both record the previous parent in From. A link from no parent
+		// leaves it empty, but so did every link written by older
+		// versions, so the parent is then looked up in history.
+		restore := ev.From
+		if restore == "" && ev.Op == "link" {
+			prior, err := ReplayEvents(issue.ID, before)
+			if err != nil {
+				return model.Event{}, fmt.Errorf("the parent before %s was not recorded: %w",
+					ev.Timestamp.Format(time.RFC3339), err)
+			}
+			restore = prior.ParentID
+		}
+		if restore != "" {
+			if err := t.validateParent(restore); err != nil {
+				return model.Event{}, err
+			}
+		}
+		undo.From = issue.ParentID
+		issue.ParentID = restore
+		if restore == "" {
+			undo.Op = "unlink"
+		} else {
+			undo.Op, undo.To = "link", restore
+		}
+
+	case "comment":
+		idx := slices.IndexFunc(issue.Comments, func(c model.Comment) bool {
+			return c.Created.Equal(ev.Timestamp) && c.By == ev.By
+		})
+		if idx < 0 {
+			return model.Event{}, fmt.Errorf("comment from %s no longer exists", ev.Timestamp.Format(time.RFC3339))
+		}
+		undo.Op, undo.Text = "uncomment", issue.Comments[idx].Text
+		issue.Comments = slices.Delete(issue.Comments, idx, idx+1)
+
+	default:
+		return model.Event{}, fmt.Errorf("cannot undo %q events", ev.Op)
+	}
+	return undo, nil
+}
+
+// UndoIssue reverts the last steps mutations recorded in an issue's
+// history. History is never rewritten: each reverted event gets a
+// compensating event marked Undo. Undo refuses to reach back past the
+// point where history was compacted. Reverting a status change is a
+// transition like any other: the restored state's guards and WIP limit
+// apply and the transition hooks run. The automation rules the reverted
+// changes trigger are applied after them. Returns the compensating
+// events.
+func (t *Tracker) UndoIssue(id string, steps int, user string) ([]model.Event, error) {
+	issue, undone, err := t.undoIssue(id, steps, user)
+	if err != nil {
+		return nil, err
+	}
+	t.postTransition(issue, undone, user)
+	if _, err := t.automateChange(issue, undone, user); err != nil {
+		return undone, err
+	}
+	return undone, nil
+}
+
+func (t *Tracker) undoIssue(id string, steps int, user string) (issue model.Issue, undone []model.Event, err error) {
+	if steps < 1 {
+		return model.Issue{}, nil, fmt.Errorf("steps must be at least 1")
+	}
+	err = t.withPreTransition(user, func(approved *hookPlan) error {
+		issue, undone, err = t.tryUndoIssue(id, steps, user, approved)
+		return err
+	})
+	return issue, undone, err
+}
+
+// tryUndoIssue is one attempt of undoIssue; see withPreTransition.
+func (t *Tracker) tryUndoIssue(id string, steps int, user string, approved *hookPlan) (model.Issue, []model.Event, error) {
+	held := len(t.Config.WIPLimits) > 0 && !t.IgnoreWIPLimits
+	if held {
+		// The states undo restores aren't known until the history is
+		// read under the issue lock; see lockState.
+		unlock, err := t.LockTracker()
+		if err != nil {
+			return model.Issue{}, nil, err
+		}
+		defer unlock()
+	}
+	unlock, err := t.lockIssue(id, held)
+	if err != nil {
+		return model.Issue{}, nil, err
+	}
+	defer unlock()
+
+	issue, err := t.LoadIssue(id)
+	if err != nil {
+		return model.Issue{}, nil, err
+	}
+	events, err := t.LoadEvents(id)
+	if err != nil {
+		return model.Issue{}, nil, err
+	}
+	targets, err := undoTargets(events, steps)
+	if err != nil {
+		return model.Issue{}, nil, err
+	}
+
+	now := time.Now().UTC()
+	var undone []model.Event
+	stored := issue
+	for _, step := range targets {
+		for _, i := range step {
+			ev := events[i]
+			undo, err := t.revert(&issue, ev, events[:i], len(step) > 1, now, user)
+			if err != nil {
+				return model.Issue{}, nil, fmt.Errorf("undoing %s: %w", ev.Op, err)
+			}
+			undone = append(undone, undo)
+		}
+	}
+	if err := t.checkStateMapping(stored, issue); err != nil {
+		return model.Issue{}, nil, fmt.Errorf("undoing edit: %w", err)
+	}
+
+	issue.Updated = now
+	if err := t.beginTransition(issue, undone, approved); err != nil {
+		return model.Issue{}, nil, fmt.Errorf("undoing status: %w", err)
+	}
+	if err := t.saveIssue(&issue); err != nil {
+		return model.Issue{}, nil, err
+	}
+	for _, ev := range undone {
+		if err := t.AppendEvent(id, ev); err != nil {
+			return model.Issue{}, nil, err
+		}
+	}
+	return issue, undone, nil
+}
@@ -0,0 +1,179 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestUndoIssue_RevertsInOrder(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	parent, err := tr.CreateIssue("Parent", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	issue, err := tr.CreateIssue("Original", "", "", 2, []string{"a"}, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "active", "agent"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	base, err := tr.LoadIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	edited := base
+	edited.Title = "Renamed"
+	edited.Labels = []string{"b"}
+	if _, _, err := tr.SaveEdit(base, edited, "agent"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, err := tr.LinkIssue(issue.ID, parent.ID, "agent"); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if _, err := tr.AddComment(issue.ID, "oops", "agent"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+
+	undone, err := tr.UndoIssue(issue.ID, 2, "testuser")
+	if err != nil {
+		t.Fatalf("undo 2: %v", err)
+	}
+	if len(undone) != 2 || undone[0].Op != "uncomment" || undone[1].Op != "unlink" {
+		t.Errorf("undone: got %+v", undone)
+	}
+	got, _ := tr.LoadIssue(issue.ID)
+	if len(got.Comments) != 0 || got.ParentID != "" {
+		t.Errorf("after undo 2: comments %v, parent %q", got.Comments, got.ParentID)
+	}
+
+	// The next undo continues past the events already undone.
+	if _, err := tr.UndoIssue(issue.ID, 2, "testuser"); err != nil {
+		t.Fatalf("undo edit and status: %v", err)
+	}
+	got, _ = tr.LoadIssue(issue.ID)
+	if got.Title != "Original" || strings.Join(got.Labels, ",") != "a" || got.Status != "open" {
+		t.Errorf("after full undo: got %+v", got)
+	}
+
+	if _, err := tr.UndoIssue(issue.ID, 1, "testuser"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("undo past create: got %v, want ErrNothingToUndo", err)
+	}
+
+	events, _ := tr.LoadEvents(issue.ID)
+	undos := 0
+	for _, ev := range events {
+		if ev.Undo {
+			undos++
+		}
+	}
+	if undos != 4 || len(events) != 9 {
+		t.Errorf("history should keep originals and add compensating events: %d events, %d undo", len(events), undos)
+	}
+}
+
+func TestUndoIssue_StopsAtCompaction(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Done", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "done", "testuser"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := tr.CompactIssue(issue.ID); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if _, err := tr.SetStatus(issue.ID, "open", "testuser"); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	if _, err := tr.UndoIssue(issue.ID, 1, "testuser"); err != nil {
+		t.Fatalf("undo reopen: %v", err)
+	}
+	_, err = tr.UndoIssue(issue.ID, 1, "testuser")
+	if err == nil || !strings.Contains(err.Error(), "compaction") {
+		t.Errorf("undo past compaction: got %v", err)
+	}
+	got, _ := tr.LoadIssue(issue.ID)
+	if got.Status != "done" {
+		t.Errorf("status: got %q, want done", got.Status)
+	}
+}
+
+func TestUndoIssue_LinkWithoutRecordedParent(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	create := func(title string) model.Issue {
+		t.Helper()
+		issue, err := tr.CreateIssue(title, "", "", 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return issue
+	}
+	p1, p2, child := create("P1"), create("P2"), create("Child")
+	if _, err := tr.LinkIssue(child.ID, p1.ID, "testuser"); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	// Re-parent the way older versions did, without recording From.
+	reparent := func(history string) {
+		t.Helper()
+		issue, _ := tr.LoadIssue(child.ID)
+		issue.ParentID = p2.ID
+		if err := tr.SaveIssue(issue); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if err := tr.Store.WriteEvents(child.ID, []byte(history)); err != nil {
+			t.Fatalf("write history: %v", err)
+		}
+	}
+	events, _ := tr.LoadEvents(child.ID)
+	var replayable strings.Builder
+	for _, ev := range events {
+		data, _ := json.Marshal(ev)
+		replayable.Write(append(data, '\n'))
+	}
+	legacyLink := `{"ts":"2024-01-03T00:00:00Z","op":"link","to":"` + p2.ID + `","by":"old"}` + "\n"
+
+	reparent(replayable.String() + legacyLink)
+	if _, err := tr.UndoIssue(child.ID, 1, "testuser"); err != nil {
+		t.Fatalf("undo replayable re-parent: %v", err)
+	}
+	if got, _ := tr.LoadIssue(child.ID); got.ParentID != p1.ID {
+		t.Errorf("parent after undo = %q, want %q", got.ParentID, p1.ID)
+	}
+
+	reparent(`{"ts":"2024-01-01T00:00:00Z","op":"create","by":"old"}
+{"ts":"2024-01-02T00:00:00Z","op":"link","to":"` + p1.ID + `","by":"old"}
+` + legacyLink)
+	if _, err := tr.UndoIssue(child.ID, 1, "testuser"); err == nil || !strings.Contains(err.Error(), "not recorded") {
+		t.Fatalf("undo legacy re-parent: got %v, want refusal", err)
+	}
+	if got, _ := tr.LoadIssue(child.ID); got.ParentID != p2.ID {
+		t.Errorf("refused undo changed parent to %q", got.ParentID)
+	}
+}
+
+func TestUndoIssue_ValidatesTransition(t *testing.T) {
+	tr := New(model.DefaultConfig(), NewMemoryStore())
+	issue, err := tr.CreateIssue("Done", "", "", 1, nil, "", "", "testuser")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, s := range []string{"active", "done"} {
+		if _, err := tr.SetStatus(issue.ID, s, "testuser"); err != nil {
+			t.Fatalf("move to %s: %v", s, err)
+		}
+	}
+	// done → active is not a transition the workflow allows.
+	if _, err := tr.UndoIssue(issue.ID, 1, "testuser"); err == nil {
+		t.Fatal("undo done: want refusal")
+	}
+	if got, _ := tr.LoadIssue(issue.ID); got.Status != "done" {
+		t.Errorf("status after refused undo = %q", got.Status)
+	}
+}
@@ -0,0 +1,100 @@
+package tracker
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+// WIPUsage is the load on a state that has a WIP limit.
+type WIPUsage struct {
+	State string
+	Limit model.WIPLimit
+	Count int
+	// Assignees counts the issues in State by assignee.
+	Assignees map[string]int
+}
+
+// String formats the usage as "active 3/5 (alice 2/2)", leaving out the
+// parts without a limit.
+func (u WIPUsage) String() string {
+	s := fmt.Sprintf("%s %d", u.State, u.Count)
+	if u.Limit.Total > 0 {
+		s += fmt.Sprintf("/%d", u.Limit.Total)
+	}
+	if u.Limit.PerAssignee > 0 && len(u.Assignees) > 0 {
+		var parts []string
+		for _, a := range slices.Sorted(maps.Keys(u.Assignees)) {
+			parts = append(parts, fmt.Sprintf("%s %d/%d", a, u.Assignees[a], u.Limit.PerAssignee))
+		}
+		s += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return s
+}
+
+// WIPLoad returns the load on each state with a WIP limit, in StateNames
+// order.
+func WIPLoad(cfg model.Config, issues []model.Issue) []WIPUsage {
+	var load []WIPUsage
+	for _, state := range cfg.StateNames() {
+		if limit, ok := cfg.WIPLimits[state]; ok {
+			load = append(load, wipUsage(state, limit, issues))
+		}
+	}
+	return load
+}
+
+func wipUsage(state string, limit model.WIPLimit, issues []model.Issue) WIPUsage {
+	u := WIPUsage{State: state, Limit: limit, Assignees: make(map[string]int)}
+	for _, issue := range issues {
+		if issue.Status != state {
+			continue
+		}
+		u.Count++
+		if issue.Assignee != "" {
+			u.Assignees[issue.Assignee]++
+		}
+	}
+	return u
+}
+
+// WIPLimitError reports a move into a state that is at its WIP limit,
+// overall or, when Assignee is set, for that assignee.
+type WIPLimitError struct {
+	State    string
+	Assignee string
+	Count    int
+	Limit    int
+}
+
+func (e *WIPLimitError) Error() string {
+	if e.Assignee != "" {
+		return fmt.Sprintf("%s already has %d/%d issues in %q; finish one first or use --force", e.Assignee, e.Count, e.Limit, e.State)
+	}
+	return fmt.Sprintf("%q is at its WIP limit (%d/%d); finish an issue first or use --force", e.State, e.Count, e.Limit)
+}
+
+// checkWIPLimit returns a *WIPLimitError if moving issue into state would
+// take it over its limit, unless IgnoreWIPLimits is set.
+func (t *Tracker) checkWIPLimit(issue model.Issue, state string) error {
+	limit, ok := t.Config.WIPLimits[state]
+	if !ok || t.IgnoreWIPLimits {
+		return nil
+	}
+	issues, err := t.ListSummaries()
+	if err != nil {
+		return err
+	}
+	issues = slices.DeleteFunc(issues, func(i model.Issue) bool { return i.ID == issue.ID })
+	u := wipUsage(state, limit, issues)
+	if limit.Total > 0 && u.Count >= limit.Total {
+		return &WIPLimitError{State: state, Count: u.Count, Limit: limit.Total}
+	}
+	if n := u.Assignees[issue.Assignee]; limit.PerAssignee > 0 && issue.Assignee != "" && n >= limit.PerAssignee {
+		return &WIPLimitError{State: state, Assignee: issue.Assignee, Count: n, Limit: limit.PerAssignee}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jfmyers9/work/internal/model"
+)
+
+func TestSetStatus_WIPLimits(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.WIPLimits = map[string]model.WIPLimit{
+		"active": {Total: 3, PerAssignee: 1},
+		"review": {Total: 1},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	create := func(title, assignee string) model.Issue {
+		t.Helper()
+		issue, err := tr.CreateIssue(title, "", assignee, 2, nil, "", "", "testuser")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return issue
+	}
+	a1, a2, b1 := create("A1", "alice"), create("A2", "alice"), create("B1", "bob")
+
+	if _, err := tr.SetStatus(a1.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start a1: %v", err)
+	}
+	_, err := tr.SetStatus(a2.ID, "active", "testuser")
+	var wipErr *WIPLimitError
+	if !errors.As(err, &wipErr) || wipErr.Assignee != "alice" || wipErr.Count != 1 || wipErr.Limit != 1 {
+		t.Fatalf("start a2: got %v, want alice's limit", err)
+	}
+	if _, err := tr.SetStatus(b1.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start b1: %v", err)
+	}
+
+	tr.IgnoreWIPLimits = true
+	if _, err := tr.SetStatus(a2.ID, "active", "testuser"); err != nil {
+		t.Fatalf("forced start a2: %v", err)
+	}
+	tr.IgnoreWIPLimits = false
+
+	// The state is now full, so even an unassigned issue is refused.
+	c := create("C", "")
+	if _, err := tr.SetStatus(c.ID, "active", "testuser"); !errors.As(err, &wipErr) || wipErr.Assignee != "" || wipErr.Count != 3 {
+		t.Fatalf("start c: got %v, want total limit", err)
+	}
+
+	if _, err := tr.SetStatus(a1.ID, "review", "testuser"); err != nil {
+		t.Fatalf("review a1: %v", err)
+	}
+	if _, err := tr.SetStatus(c.ID, "active", "testuser"); err != nil {
+		t.Fatalf("start c after a1 moved on: %v", err)
+	}
+
+	issues, err := tr.ListIssues()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	load := WIPLoad(cfg, issues)
+	if len(load) != 2 || load[0].String() != "active 3/3 (alice 1/1, bob 1/1)" || load[1].String() != "review 1/1" {
+		t.Errorf("load = %v", load)
+	}
+}
+
+func TestWIPLimits_UndoAndTypeChange(t *testing.T) {
+	cfg := model.DefaultConfig()
+	cfg.WIPLimits = map[string]model.WIPLimit{"review": {Total: 1}}
+	cfg.Workflows = map[string]model.Workflow{
+		"bug": {Transitions: map[string][]string{"triage": {"review"}, "review": {"triage"}}, DefaultState: "triage"},
+	}
+	tr := New(cfg, NewMemoryStore())
+
+	move := func(id string, states ...string) {
+		t.Helper()
+		for _, s := range states {
+			if _, err := tr.SetStatus(id, s, "testuser"); err != nil {
+				t.Fatalf("move %s to %s: %v", id, s, err)
+			}
+		}
+	}
+	a, _ := tr.CreateIssue("A", "", "", 2, nil, "feature", "", "testuser")
+	b, _ := tr.CreateIssue("B", "", "", 2, nil, "feature", "", "testuser")
+	move(b.ID, "active", "review", "active")
+	move(a.ID, "active", "review")
+
+	var wipErr *WIPLimitError
+	if _, err := tr.UndoIssue(b.ID, 1, "testuser"); !errors.As(err, &wipErr) {
+		t.Fatalf("undo into full review: got %v, want WIP limit", err)
+	}
+	if loaded, _ := tr.LoadIssue(b.ID); loaded.Status != "active" {
+		t.Errorf("refused undo left b %s", loaded.Status)
+	}
+
+	c, _ := tr.CreateIssue("C", "", "", 2, nil, "feature", "", "testuser")
+	edited := c
+	edited.Type, edited.Status = "bug", "review"
+	if _, _, err := tr.SaveEdit(c, edited, "testuser"); !errors.As(err, &wipErr) {
+		t.Fatalf("map into full review: got %v, want WIP limit", err)
+	}
+	if loaded, _ := tr.LoadIssue(c.ID); loaded.Type != "feature" || loaded.Status != "open" {
+		t.Errorf("refused edit left c %s/%s", loaded.Type, loaded.Status)
+	}
+}
@@ -9,20 +9,29 @@ import (
 )
 
 var (
-	statuses = []string{"", "open", "active", "review", "done", "cancelled"}
-	types    = []string{"", "feature", "bug", "chore"}
-	sorts    = []string{"priority", "created", "updated", "title"}
+	types = []string{"", "feature", "bug", "chore"}
+	sorts = []string{"priority", "created", "updated", "title"}
 )
 
 type filterState struct {
+	// statuses are the states to cycle through, after "" for any.
+	statuses   []string
+	terminal   []string
 	statusIdx  int
 	typeIdx    int
 	sortIdx    int
 	showClosed bool
 }
 
+func newFilterState(cfg model.Config) filterState {
+	return filterState{
+		statuses: append([]string{""}, cfg.StateNames()...),
+		terminal: cfg.TerminalStates(),
+	}
+}
+
 func (f *filterState) cycleStatus() {
-	f.statusIdx = (f.statusIdx + 1) % len(statuses)
+	f.statusIdx = (f.statusIdx + 1) % len(f.statuses)
 }
 
 func (f *filterState) cycleType() {
@@ -41,11 +50,11 @@ func (f *filterState) clear() {
 
 func (f filterState) apply(issues []model.Issue) []model.Issue {
 	opts := tracker.FilterOptions{
-		Status: statuses[f.statusIdx],
+		Status: f.statuses[f.statusIdx],
 		Type:   types[f.typeIdx],
 	}
 	if !f.showClosed && f.statusIdx == 0 {
-		opts.ExcludeStatuses = []string{"done", "cancelled"}
+		opts.ExcludeStatuses = f.terminal
 	}
 	filtered := tracker.FilterIssues(issues, opts)
 	tracker.SortIssues(filtered, sorts[f.sortIdx])
@@ -57,8 +66,8 @@ func (f filterState) view() string {
 
 	if f.statusIdx == 0 && !f.showClosed {
 		parts = append(parts, filterTagStyle.Render("open"))
-	} else if statuses[f.statusIdx] != "" {
-		parts = append(parts, filterTagStyle.Render(statuses[f.statusIdx]))
+	} else if f.statuses[f.statusIdx] != "" {
+		parts = append(parts, filterTagStyle.Render(f.statuses[f.statusIdx]))
 	} else if f.showClosed {
 		parts = append(parts, filterTagStyle.Render("all"))
 	}
@@ -32,7 +32,7 @@ func (h helpModel) View(width int) string {
 			title: "Actions",
 			keys: [][2]string{
 				{"s", "change status"},
-				{"a/d/r/x", "active / done / review / cancel"},
+				{"a/d/r/x", "start / done / review / cancel"},
 				{"c", "add comment"},
 				{"e", "edit in $EDITOR"},
 				{"n", "new issue"},
@@ -43,7 +43,7 @@ func (h helpModel) View(width int) string {
 			title: "Filters (list)",
 			keys: [][2]string{
 				{"f", "cycle status filter"},
-				{"A", "toggle done/cancelled"},
+				{"A", "toggle terminal states"},
 				{"t", "cycle type filter"},
 				{"o", "cycle sort order"},
 				{"F", "clear filters"},
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jfmyers9/work/internal/model"
+	"github.com/jfmyers9/work/internal/tracker"
 )
 
 type historyModel struct {
@@ -68,12 +69,18 @@ func renderHistory(issueID string, events []model.Event, width int) string {
 			line,
 			helpStyle.Render(by),
 		))
+		b.WriteString(renderChanges(ev.Changes))
 	}
 
 	return b.String()
 }
 
 func formatEvent(ev model.Event) string {
+	if ev.Undo {
+		undone := ev
+		undone.Undo = false
+		return helpStyle.Render("(undo) ") + formatEvent(undone)
+	}
 	switch ev.Op {
 	case "status":
 		return styledStatus(ev.From) + " → " + styledStatus(ev.To)
@@ -92,3 +99,25 @@ func formatEvent(ev model.Event) string {
 		return ""
 	}
 }
+
+// renderChanges shows the before/after values of an edit event, with a
+// line diff for multi-line values such as the description.
+func renderChanges(changes []model.FieldChange) string {
+	var b strings.Builder
+	lines := tracker.RenderChanges(changes, func(op byte, s string) string {
+		switch op {
+		case ':':
+			return labelStyle.Render(s)
+		case '-':
+			return diffDelStyle.Render(s)
+		case '+':
+			return diffAddStyle.Render(s)
+		default:
+			return helpStyle.Render(s)
+		}
+	})
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/jfmyers9/work/internal/model"
-	"github.com/jfmyers9/work/internal/tracker"
 )
 
 // Table cell styles for custom rendering. We bypass the bubbles
@@ -46,18 +45,13 @@ type listModel struct {
 	scrollOffset int
 }
 
-func newListModel(issues []model.Issue, width int) listModel {
+func newListModel(cfg model.Config, issues []model.Issue, shortIDs map[string]string, width int) listModel {
 	si := textinput.New()
 	si.Placeholder = "search..."
 	si.CharLimit = 128
 	si.Width = 40
 
-	ids := make([]string, len(issues))
-	for i, issue := range issues {
-		ids[i] = issue.ID
-	}
-
-	m := listModel{allIssues: issues, shortIDs: tracker.MinPrefixes(ids), search: si, width: width, tableHeight: 20}
+	m := listModel{allIssues: issues, shortIDs: shortIDs, filters: newFilterState(cfg), search: si, width: width, tableHeight: 20}
 	m.table = newTable(width)
 	m.rebuildRows()
 	return m
@@ -1,11 +1,11 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -33,6 +33,13 @@ type editorDoneMsg struct {
 	err     error
 }
 
+// editorConflictMsg re-opens the editor when the issue was changed by
+// someone else while it was being edited.
+type editorConflictMsg struct {
+	base    model.Issue
+	content string
+}
+
 type issueLinkMsg struct {
 	childID string
 	err     error
@@ -71,23 +78,39 @@ type rootModel struct {
 	history      historyModel
 	help         helpModel
 	issues       []model.Issue
+	prefixes     *tracker.PrefixTree
+	shortIDs     map[string]string
 	user         string
 	editor       string
 	statusMsg    string
-	width        int
-	height       int
+	// output holds what hooks and automation rules printed, to be
+	// shown in the status bar.
+	output *outputBuffer
+	// pendingStatus is the state to move to once the reason comment a
+	// guard asked for has been added.
+	pendingStatus string
+	width         int
+	height        int
 }
 
 func newModel(t *tracker.Tracker, issues []model.Issue, user, editorCmd string) rootModel {
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+	prefixes := tracker.NewPrefixTree(ids)
+	shortIDs := prefixes.ShortestAll()
 	return rootModel{
-		tracker: t,
-		screen:  screenList,
-		list:    newListModel(issues, 80),
-		issues:  issues,
-		user:    user,
-		editor:  editorCmd,
-		width:   80,
-		height:  24,
+		tracker:  t,
+		screen:   screenList,
+		list:     newListModel(t.Config, issues, shortIDs, 80),
+		issues:   issues,
+		prefixes: prefixes,
+		shortIDs: shortIDs,
+		user:     user,
+		editor:   editorCmd,
+		width:    80,
+		height:   24,
 	}
 }
 
@@ -96,16 +119,33 @@ func (m rootModel) Init() tea.Cmd {
 }
 
 func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if rm, ok := next.(rootModel); ok {
+		if out := rm.output.take(); out != "" {
+			if rm.statusMsg != "" {
+				out = rm.statusMsg + " — " + out
+			}
+			rm.statusMsg = out
+		}
+		next = rm
+	}
+	return next, cmd
+}
+
+func (m rootModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case statusChangedMsg:
 		m.statusMsg = fmt.Sprintf("Status → %s", msg.status)
 		m.reloadIssues()
 		m.screen = m.prevScreen
+		if msg.guard != nil {
+			return m.meetGuards(msg.issueID, msg.target, msg.guard)
+		}
 		if m.screen == screenDetail {
 			issue, err := m.tracker.LoadIssue(msg.issueID)
 			if err == nil {
 				children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-				m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+				m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 			}
 		}
 		return m, nil
@@ -114,22 +154,30 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMsg = "Comment added"
 		m.reloadIssues()
 		m.screen = m.prevScreen
+		if m.pendingStatus != "" {
+			status := m.pendingStatus
+			m.pendingStatus = ""
+			return m, m.executeStatusChange(msg.issueID, status)
+		}
 		if m.screen == screenDetail {
 			issue, err := m.tracker.LoadIssue(msg.issueID)
 			if err == nil {
 				children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-				m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+				m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 			}
 		}
 		return m, nil
 
 	case issueCreatedMsg:
 		m.reloadIssues()
-		shortID := tracker.MinPrefix(msg.id, m.issueIDs())
+		shortID := m.prefixes.Shortest(msg.id)
 		m.statusMsg = fmt.Sprintf("Created %s: %s", shortID, msg.title)
 		m.screen = screenList
 		return m, nil
 
+	case editorConflictMsg:
+		return m.runEditor(msg.base, msg.content)
+
 	case editorDoneMsg:
 		m.reloadIssues()
 		if msg.err != nil {
@@ -140,7 +188,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				issue, err := m.tracker.LoadIssue(msg.issueID)
 				if err == nil {
 					children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-					m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+					m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 				}
 			}
 		}
@@ -159,7 +207,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			issue, err := m.tracker.LoadIssue(msg.childID)
 			if err == nil {
 				children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-				m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+				m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 			}
 		}
 		return m, nil
@@ -175,7 +223,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			issue, err := m.tracker.LoadIssue(msg.childID)
 			if err == nil {
 				children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-				m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+				m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 			}
 		}
 		return m, nil
@@ -231,12 +279,14 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				text := m.commentInput.textarea.Value()
 				if text == "" {
 					m.screen = m.prevScreen
+					m.pendingStatus = ""
 					return m, nil
 				}
 				issueID := m.commentInput.issueID
 				return m, m.executeAddComment(issueID, text)
 			case "esc":
 				m.screen = m.prevScreen
+				m.pendingStatus = ""
 				return m, nil
 			}
 			var cmd tea.Cmd
@@ -291,7 +341,8 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.confirm, confirmed, _ = m.confirm.Update(msg)
 			if msg.String() == "y" || msg.String() == "Y" {
 				if confirmed {
-					return m, m.executeStatusChange(m.confirm.issueID, "cancelled")
+					m.screen = m.prevScreen
+					return m.quickStatus(model.CategoryCancelled)
 				}
 			} else if msg.String() == "n" || msg.String() == "N" || msg.String() == "esc" {
 				m.screen = m.prevScreen
@@ -314,11 +365,11 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "c":
 			return m.openComment()
 		case "a":
-			return m.quickStatus("active")
+			return m.quickStatus(model.CategoryInProgress)
 		case "d":
-			return m.quickStatus("done")
+			return m.quickStatus(model.CategoryDone)
 		case "r":
-			return m.quickStatus("review")
+			return m.quickStatus(model.CategoryReview)
 		case "n":
 			if m.screen == screenList {
 				m.screen = screenCreate
@@ -337,7 +388,10 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.statusMsg = "History: " + err.Error()
 					return m, nil
 				}
-				short := tracker.MinPrefix(issueID, m.issueIDs())
+				if warnings := m.tracker.Warnings(); len(warnings) > 0 {
+					m.statusMsg = fmt.Sprintf("History: skipped %d unreadable line(s); run work doctor", len(warnings))
+				}
+				short := m.prefixes.Shortest(issueID)
 				m.history = newHistoryModel(
 					short,
 					events, m.width, m.height,
@@ -356,7 +410,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					issue, err := m.tracker.LoadIssue(row[0])
 					if err == nil {
 						children := tracker.FilterIssues(m.issues, tracker.FilterOptions{ParentID: issue.ID})
-						m.detail = newDetailModel(issue, children, m.width, m.height, tracker.MinPrefixes(m.issueIDs()))
+						m.detail = newDetailModel(issue, children, m.width, m.height, m.shortIDs)
 						m.screen = screenDetail
 					}
 				}
@@ -393,12 +447,37 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m rootModel) issueIDs() []string {
-	ids := make([]string, len(m.issues))
-	for i, issue := range m.issues {
-		ids[i] = issue.ID
+// syncPrefixes brings the cached prefix tree and shortest unique
+// prefixes in line with issues after a reload.
+// Only IDs that were created, purged or renamed since the last sync are
+// touched, and only IDs sharing a first character with one of them can
+// have a different shortest prefix, so just those are recomputed.
+func (m *rootModel) syncPrefixes(issues []model.Issue) {
+	current := make(map[string]bool, len(issues))
+	var changed []string
+	for _, issue := range issues {
+		current[issue.ID] = true
+		if m.prefixes.Insert(issue.ID) {
+			changed = append(changed, issue.ID)
+		}
+	}
+	for id := range m.shortIDs {
+		if !current[id] {
+			m.prefixes.Delete(id)
+			delete(m.shortIDs, id)
+			changed = append(changed, id)
+		}
+	}
+	refreshed := make(map[byte]bool)
+	for _, id := range changed {
+		if id == "" || refreshed[id[0]] {
+			continue
+		}
+		refreshed[id[0]] = true
+		for _, other := range m.prefixes.Matches(id[:1]) {
+			m.shortIDs[other] = m.prefixes.Shortest(other)
+		}
 	}
-	return ids
 }
 
 func (m *rootModel) reloadIssues() {
@@ -406,9 +485,10 @@ func (m *rootModel) reloadIssues() {
 	if err == nil {
 		tracker.SortIssues(issues, "priority")
 		m.issues = issues
+		m.syncPrefixes(issues)
 		filters := m.list.filters
 		query := m.list.query
-		m.list = newListModel(issues, m.width)
+		m.list = newListModel(m.tracker.Config, issues, m.shortIDs, m.width)
 		m.list.filters = filters
 		m.list.query = query
 		m.list.rebuildRows()
@@ -441,15 +521,40 @@ func (m rootModel) openComment() (tea.Model, tea.Cmd) {
 	return m, m.commentInput.textarea.Focus()
 }
 
-func (m rootModel) quickStatus(status string) (tea.Model, tea.Cmd) {
+// quickStatus moves the selected issue to the first state in category
+// that its current state may transition to.
+func (m rootModel) quickStatus(category string) (tea.Model, tea.Cmd) {
 	id, _, ok := m.selectedIssue()
 	if !ok {
 		return m, nil
 	}
+	status, ok := m.transitionTo(id, category)
+	if !ok {
+		m.statusMsg = fmt.Sprintf("No %s state reachable from here", category)
+		return m, nil
+	}
 	m.prevScreen = m.screen
 	return m, m.executeStatusChange(id, status)
 }
 
+func (m rootModel) transitionTo(id, category string) (string, bool) {
+	var current model.Issue
+	if m.screen == screenDetail && m.detail.issue.ID == id {
+		current = m.detail.issue
+	}
+	for _, issue := range m.issues {
+		if current.ID == "" && issue.ID == id {
+			current = issue
+		}
+	}
+	for _, s := range m.tracker.Config.Workflow(current.Type).Transitions[current.Status] {
+		if m.tracker.Config.Category(s) == category {
+			return s, true
+		}
+	}
+	return "", false
+}
+
 func (m rootModel) openConfirm() (tea.Model, tea.Cmd) {
 	id, _, ok := m.selectedIssue()
 	if !ok {
@@ -472,7 +577,7 @@ func (m rootModel) executeAddComment(issueID, text string) tea.Cmd {
 }
 
 func (m rootModel) openStatusPicker() (tea.Model, tea.Cmd) {
-	var issueID, currentStatus string
+	var issueID, currentStatus, issueType string
 
 	switch m.screen {
 	case screenList:
@@ -482,14 +587,16 @@ func (m rootModel) openStatusPicker() (tea.Model, tea.Cmd) {
 		}
 		issueID = row[0]
 		currentStatus = row[1]
+		issueType = row[2]
 	case screenDetail:
 		issueID = m.detail.issue.ID
 		currentStatus = m.detail.issue.Status
+		issueType = m.detail.issue.Type
 	default:
 		return m, nil
 	}
 
-	transitions := m.tracker.Config.Transitions[currentStatus]
+	transitions := m.tracker.Config.Workflow(issueType).Transitions[currentStatus]
 	if len(transitions) == 0 {
 		m.statusMsg = "No transitions available"
 		return m, nil
@@ -527,23 +634,30 @@ func (m rootModel) openEditor() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	m.prevScreen = m.screen
+	return m.runEditor(issue, editor.MarshalIssue(issue))
+}
+
+// runEditor opens content in the editor and saves the result as an edit
+// of base. Conflicting concurrent changes re-open the editor with a notice.
+func (m rootModel) runEditor(base model.Issue, content string) (tea.Model, tea.Cmd) {
 	tmpFile, err := os.CreateTemp("", "work-edit-*.md")
 	if err != nil {
 		m.statusMsg = "Temp file: " + err.Error()
+		m.screen = m.prevScreen
 		return m, nil
 	}
-	content := editor.MarshalIssue(issue)
 	if _, err := tmpFile.WriteString(content); err != nil {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpFile.Name())
 		m.statusMsg = "Write: " + err.Error()
+		m.screen = m.prevScreen
 		return m, nil
 	}
 	_ = tmpFile.Close()
 
-	m.prevScreen = m.screen
 	path := tmpFile.Name()
-	issueID := issue.ID
+	issueID := base.ID
 
 	c := exec.Command(m.editor, path)
 	return m, tea.ExecProcess(c, func(err error) tea.Msg {
@@ -559,16 +673,24 @@ func (m rootModel) openEditor() (tea.Model, tea.Cmd) {
 		if parseErr != nil {
 			return editorDoneMsg{issueID: issueID, err: parseErr}
 		}
-		issue.Title = title
-		issue.Description = desc
-		issue.Type = issueType
-		issue.Assignee = assignee
-		issue.Priority = priority
-		issue.Labels = labels
-		issue.Updated = time.Now().UTC()
-		if saveErr := m.tracker.SaveIssue(issue); saveErr != nil {
+		mine := base
+		mine.Title = title
+		mine.Description = desc
+		mine.Type = issueType
+		mine.Assignee = assignee
+		mine.Priority = priority
+		mine.Labels = labels
+		current, conflicts, saveErr := m.tracker.SaveEdit(base, mine, m.user)
+		if saveErr != nil {
 			return editorDoneMsg{issueID: issueID, err: saveErr}
 		}
+		if len(conflicts) > 0 {
+			reopen, _ := tracker.MergeEdits(base, current, mine)
+			return editorConflictMsg{
+				base:    current,
+				content: editor.ConflictNotice(tracker.SavedValues(conflicts)) + editor.MarshalIssue(reopen),
+			}
+		}
 		return editorDoneMsg{issueID: issueID}
 	})
 }
@@ -605,16 +727,56 @@ func (m rootModel) executeLinkIssue(childID, parentID string) tea.Cmd {
 func (m rootModel) executeStatusChange(issueID, newStatus string) tea.Cmd {
 	return func() tea.Msg {
 		_, err := m.tracker.SetStatus(issueID, newStatus, m.user)
+		var guardErr *tracker.GuardError
+		if errors.As(err, &guardErr) {
+			return statusChangedMsg{issueID: issueID, guard: guardErr, target: newStatus}
+		}
 		if err != nil {
 			return statusChangedMsg{issueID: issueID, status: "error: " + err.Error()}
 		}
-		if newStatus == "done" || newStatus == "cancelled" {
+		if m.tracker.Config.IsTerminal(newStatus) {
 			_ = m.tracker.CompactIssue(issueID)
 		}
 		return statusChangedMsg{issueID: issueID, status: newStatus}
 	}
 }
 
+// meetGuards handles a status change refused by guards. When a reason
+// comment is all that is missing it opens the comment input and retries
+// the change once the comment is added; otherwise it shows what is
+// missing.
+func (m rootModel) meetGuards(issueID, target string, guardErr *tracker.GuardError) (tea.Model, tea.Cmd) {
+	if len(guardErr.Unmet) == 1 && guardErr.Missing(model.RequireComment) {
+		issue, err := m.tracker.LoadIssue(issueID)
+		if err != nil {
+			m.statusMsg = "error: " + err.Error()
+			return m, nil
+		}
+		m.prevScreen = m.screen
+		m.screen = screenComment
+		m.commentInput = newCommentModel(issueID, issue.Title, m.width)
+		m.commentInput.textarea.Placeholder = fmt.Sprintf("Reason for moving to %s...", target)
+		m.pendingStatus = target
+		return m, m.commentInput.textarea.Focus()
+	}
+	missing := make([]string, len(guardErr.Unmet))
+	for i, u := range guardErr.Unmet {
+		missing[i] = u.Message
+	}
+	m.statusMsg = fmt.Sprintf("error: %s %s", target, strings.Join(missing, "; "))
+	return m, nil
+}
+
+// wipSummary describes the load on states with WIP limits, for the
+// list header.
+func (m rootModel) wipSummary() string {
+	var s string
+	for _, u := range tracker.WIPLoad(m.tracker.Config, m.issues) {
+		s += " · " + u.String()
+	}
+	return s
+}
+
 func (m rootModel) renderHeader(title string) string {
 	left := headerStyle.Render("work")
 	right := headerDimStyle.Render(" " + title + " ")
@@ -651,7 +813,7 @@ func (m rootModel) View() string {
 
 	switch m.screen {
 	case screenList:
-		header = m.renderHeader("issues")
+		header = m.renderHeader("issues" + m.wipSummary())
 		body = m.list.View()
 		footer = m.renderFooter("?:help  n:new  s:status  /:search  q:quit")
 	case screenDetail:
@@ -5,11 +5,16 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jfmyers9/work/internal/tracker"
 )
 
 type statusChangedMsg struct {
 	issueID string
 	status  string
+	// guard is set when the change was refused by the new state's
+	// guards; target is the state it was refused for.
+	guard  *tracker.GuardError
+	target string
 }
 
 type statusPicker struct {
@@ -108,6 +108,10 @@ var (
 	focusedFieldStyle = lipgloss.NewStyle().Foreground(colorAccent)
 	blurredFieldStyle = lipgloss.NewStyle().Foreground(colorMuted)
 
+	// Diff
+	diffAddStyle = lipgloss.NewStyle().Foreground(colorGreen)
+	diffDelStyle = lipgloss.NewStyle().Foreground(colorRed)
+
 	// Picker
 	pickerItemStyle = lipgloss.NewStyle().Padding(0, 2)
 	pickerSelectedStyle = lipgloss.NewStyle().
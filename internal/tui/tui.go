@@ -3,24 +3,18 @@ package tui
 
 import (
 	"fmt"
-	"os"
+	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/jfmyers9/work/internal/config"
 	"github.com/jfmyers9/work/internal/tracker"
 )
 
-func Run() error {
-	wd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("getwd: %w", err)
-	}
-
-	t, err := tracker.Load(wd)
-	if err != nil {
-		return fmt.Errorf("load tracker: %w", err)
-	}
-
+// Run runs the UI on t until it exits. user is who changes are recorded
+// as and editorCmd the editor used for descriptions. What hooks and
+// automation rules print on t.HookOutput is shown in the status bar
+// instead, since the screen belongs to the UI.
+func Run(t *tracker.Tracker, user, editorCmd string) error {
 	issues, err := t.ListIssues()
 	if err != nil {
 		return fmt.Errorf("list issues: %w", err)
@@ -28,10 +22,42 @@ func Run() error {
 
 	tracker.SortIssues(issues, "priority")
 
-	cfg, _ := config.Load()
+	output := &outputBuffer{}
+	t.HookOutput = output
 
-	m := newModel(t, issues, cfg.User, cfg.Editor)
+	m := newModel(t, issues, user, editorCmd)
+	m.output = output
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err = p.Run()
 	return err
 }
+
+// outputBuffer collects what the tracker prints while the UI runs, until
+// the UI takes it to show.
+type outputBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// take returns the lines written since the last call, joined into one.
+func (b *outputBuffer) take() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lines []string
+	for line := range strings.Lines(b.buf.String()) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	b.buf.Reset()
+	return strings.Join(lines, "; ")
+}